@@ -2,6 +2,7 @@ package deque
 
 import (
 	"container/list"
+	"fmt"
 	"math/rand"
 	"testing"
 )
@@ -36,6 +37,30 @@ func BenchmarkPushFront(b *testing.B) {
 	})
 }
 
+func BenchmarkPushBackSlice(b *testing.B) {
+	vs := make([]int, 1000)
+	for i := range vs {
+		vs[i] = i
+	}
+
+	b.Run("PushBack", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < b.N; i++ {
+			for _, v := range vs {
+				dq.PushBack(v)
+			}
+			dq.Clear()
+		}
+	})
+	b.Run("PushBackSlice", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < b.N; i++ {
+			dq.PushBackSlice(vs)
+			dq.Clear()
+		}
+	})
+}
+
 func BenchmarkRandom(b *testing.B) {
 	const nn = 10000
 	a := make([]int, nn)
@@ -78,3 +103,212 @@ func BenchmarkRandom(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkPeek(b *testing.B) {
+	for _, n := range []int{1000, 100000, 10000000} {
+		a := make([]int, 10000)
+		for i := range a {
+			a[i] = rand.Intn(n)
+		}
+
+		dq1 := NewDeque[int]()
+		for i := 0; i < n; i++ {
+			dq1.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("Deque/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dq1.Peek(a[i%len(a)])
+			}
+		})
+
+		dq2 := NewDeque[int]()
+		for i := 0; i < n; i++ {
+			dq2.PushBack(i)
+		}
+		for i := 0; i < 1000; i++ {
+			dq2.Insert(rand.Intn(n), i)
+		}
+		b.Run(fmt.Sprintf("Deque/%d/Sparse", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dq2.Peek(a[i%len(a)])
+			}
+		})
+	}
+}
+
+func BenchmarkReplace(b *testing.B) {
+	for _, n := range []int{1000, 100000, 10000000} {
+		a := make([]int, 10000)
+		for i := range a {
+			a[i] = rand.Intn(n)
+		}
+
+		dq := NewDeque[int]()
+		for i := 0; i < n; i++ {
+			dq.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("Deque/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dq.Replace(a[i%len(a)], i)
+			}
+		})
+	}
+}
+
+func BenchmarkInsertRemove(b *testing.B) {
+	for _, n := range []int{1000, 100000, 2000000} {
+		a := make([]int, 10000)
+		for i := range a {
+			a[i] = rand.Intn(n)
+		}
+
+		dq := NewDeque[int]()
+		for i := 0; i < n; i++ {
+			dq.PushBack(i)
+		}
+		b.Run(fmt.Sprintf("Deque/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				idx := a[i%len(a)]
+				dq.Insert(idx, i)
+				dq.Remove(idx)
+			}
+		})
+	}
+}
+
+func BenchmarkRotate(b *testing.B) {
+	const nn = 100000
+	b.Run("PopFront+PushBack", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < nn; i++ {
+			dq.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < nn/3; j++ {
+				dq.PushBack(dq.PopFront())
+			}
+		}
+	})
+	b.Run("Rotate", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < nn; i++ {
+			dq.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			dq.Rotate(nn / 3)
+		}
+	})
+}
+
+func BenchmarkBoundedQueue(b *testing.B) {
+	for _, n := range []int{16, 256} {
+		b.Run(fmt.Sprintf("Deque/%d", n), func(b *testing.B) {
+			dq := NewDeque[int]()
+			for i := 0; i < n; i++ {
+				dq.PushBack(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				dq.PushBack(i)
+				dq.PopFront()
+			}
+		})
+		b.Run(fmt.Sprintf("RingDeque/%d", n), func(b *testing.B) {
+			rd := NewRingDeque[int](n + 1)
+			for i := 0; i < n; i++ {
+				rd.PushBack(i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rd.PushBack(i)
+				rd.PopFront()
+			}
+		})
+	}
+}
+
+func BenchmarkLastN(b *testing.B) {
+	const nn = 1024
+	b.Run("Deque", func(b *testing.B) {
+		dq := NewDeque[int](WithMaxLen(nn))
+		for i := 0; i < b.N; i++ {
+			dq.PushBack(i)
+		}
+	})
+	b.Run("RingDeque", func(b *testing.B) {
+		rd := NewRingDeque[int](nn)
+		for i := 0; i < b.N; i++ {
+			rd.PushBack(i)
+		}
+	})
+}
+
+func BenchmarkRingRandom(b *testing.B) {
+	const nn = 10000
+	a := make([]int, nn)
+	for i := 0; i < nn; i++ {
+		a[i] = rand.Int()
+	}
+
+	b.Run("Deque", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < b.N; i++ {
+			switch a[i%nn] % 4 {
+			case 0:
+				dq.PushBack(i)
+			case 1:
+				dq.PushFront(i)
+			case 2:
+				dq.TryPopBack()
+			case 3:
+				dq.TryPopFront()
+			}
+		}
+	})
+	b.Run("RingDeque", func(b *testing.B) {
+		rd := NewRingDeque[int](16, WithGrowth())
+		for i := 0; i < b.N; i++ {
+			switch a[i%nn] % 4 {
+			case 0:
+				rd.PushBack(i)
+			case 1:
+				rd.PushFront(i)
+			case 2:
+				rd.TryPopBack()
+			case 3:
+				rd.TryPopFront()
+			}
+		}
+	})
+}
+
+func BenchmarkRingPeek(b *testing.B) {
+	const nn = 100000
+	a := make([]int, 10000)
+	for i := range a {
+		a[i] = rand.Intn(nn)
+	}
+
+	b.Run("Deque", func(b *testing.B) {
+		dq := NewDeque[int]()
+		for i := 0; i < nn; i++ {
+			dq.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			dq.Peek(a[i%len(a)])
+		}
+	})
+	b.Run("RingDeque", func(b *testing.B) {
+		rd := NewRingDeque[int](nn)
+		for i := 0; i < nn; i++ {
+			rd.PushBack(i)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rd.Peek(a[i%len(a)])
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package deque
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by the methods of BlockingDeque after it is closed and,
+// for the pop methods, after all its values have been removed.
+var ErrClosed = errors.New("deque is closed")
+
+// BlockingDeque is a goroutine-safe Deque whose pop methods can wait for values,
+// and whose push methods can wait for free space if it has a capacity.
+//
+// The waiters are served in the order they start waiting, and a change to the
+// BlockingDeque only wakes as many waiters as it can satisfy. A waiter at the
+// head of the queue holds up the waiters behind it, e.g. a DequeueManyWait call
+// waiting for 10 values keeps a PopFrontWait call behind it waiting even if
+// there are 5 values.
+type BlockingDeque[T any] struct {
+	mu        sync.Mutex
+	dq        *Deque[T]
+	callbacks callbackQueue[T]
+	capacity  int
+	closed    bool
+	takers    waitQueue
+	putters   waitQueue
+}
+
+// waitQueue is a FIFO queue of the goroutines waiting for a BlockingDeque.
+// reserved is the number of values, or free slots, promised to the waiters
+// which are woken but have not taken them yet.
+type waitQueue struct {
+	waiters  []*waiter
+	reserved int
+}
+
+type waiter struct {
+	need     int
+	ready    chan struct{}
+	woken    bool
+	reserved bool
+}
+
+// NewBlockingDeque creates a new BlockingDeque instance. capacity <= 0 means
+// the BlockingDeque has no capacity, so its push methods never wait.
+//
+// The options apply to the underlying Deque. Avoid WithMaxLen and WithMaxBytes,
+// which drop values instead of making the push methods wait. The callbacks set by
+// WithOnEvict and WithWatermarks are called after the lock of the BlockingDeque
+// is released, so they may call its methods.
+func NewBlockingDeque[T any](capacity int, opts ...Option) *BlockingDeque[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	bd := &BlockingDeque[T]{
+		dq:       NewDeque[T](opts...),
+		capacity: capacity,
+	}
+	bd.callbacks.wrap(bd.dq)
+	return bd
+}
+
+// unlock releases the lock of bd, and then calls the callbacks triggered while
+// the lock was held.
+func (bd *BlockingDeque[T]) unlock() {
+	pending := bd.callbacks.take()
+	bd.mu.Unlock()
+	for _, f := range pending {
+		f()
+	}
+}
+
+// avail returns the number of values, or free slots, the waiters in q wait for.
+func (bd *BlockingDeque[T]) avail(q *waitQueue) int {
+	if q == &bd.takers {
+		return bd.dq.count
+	}
+	if bd.capacity == 0 {
+		return maxLenUnlimited
+	}
+	return bd.capacity - bd.dq.count
+}
+
+// wait waits in q until n values, or free slots, are available to the caller,
+// or bd is closed. bd.mu must be locked when wait is called, and it is locked
+// again when wait returns.
+func (bd *BlockingDeque[T]) wait(ctx context.Context, q *waitQueue, n int) error {
+	if bd.closed || len(q.waiters) == 0 && bd.avail(q)-q.reserved >= n {
+		return nil
+	}
+
+	w := &waiter{need: n, ready: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	for {
+		bd.unlock()
+		var err error
+		select {
+		case <-w.ready:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		bd.mu.Lock()
+
+		if w.reserved {
+			q.reserved -= w.need
+		} else if !w.woken {
+			q.remove(w)
+		}
+		if err != nil {
+			// Pass the wakeup, or the place at the head of q, on to the next waiter.
+			bd.wake(q)
+			return err
+		}
+		if bd.closed || bd.avail(q)-q.reserved >= n {
+			return nil
+		}
+
+		// What w was woken for has been taken away, e.g. by an eviction of
+		// the underlying Deque. Wait again at the head of q.
+		*w = waiter{need: n, ready: make(chan struct{})}
+		q.waiters = append(q.waiters, nil)
+		copy(q.waiters[1:], q.waiters)
+		q.waiters[0] = w
+	}
+}
+
+// wake wakes the waiters at the head of q whose needs can be satisfied, or all
+// the waiters if bd is closed.
+func (bd *BlockingDeque[T]) wake(q *waitQueue) {
+	for len(q.waiters) > 0 {
+		w := q.waiters[0]
+		if !bd.closed {
+			if bd.avail(q)-q.reserved < w.need {
+				return
+			}
+			q.reserved += w.need
+			w.reserved = true
+		}
+		q.waiters[0] = nil
+		q.waiters = q.waiters[1:]
+		w.woken = true
+		close(w.ready)
+	}
+}
+
+func (q *waitQueue) remove(w *waiter) {
+	for i, x := range q.waiters {
+		if x == w {
+			copy(q.waiters[i:], q.waiters[i+1:])
+			q.waiters[len(q.waiters)-1] = nil
+			q.waiters = q.waiters[:len(q.waiters)-1]
+			return
+		}
+	}
+}
+
+// ready returns whether n values, or free slots, are available in q to a caller
+// which does not wait.
+func (bd *BlockingDeque[T]) ready(q *waitQueue, n int) bool {
+	return len(q.waiters) == 0 && bd.avail(q)-q.reserved >= n
+}
+
+// PushBackWait adds v at the back of bd. If bd is full, it waits until there is
+// free space, ctx is done or bd is closed. It returns ErrClosed if bd is closed,
+// or ctx.Err() if ctx is done before v is added.
+func (bd *BlockingDeque[T]) PushBackWait(ctx context.Context, v T) error {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if err := bd.wait(ctx, &bd.putters, 1); err != nil {
+		return err
+	}
+	if bd.closed {
+		return ErrClosed
+	}
+	bd.dq.PushBack(v)
+	bd.wake(&bd.takers)
+	return nil
+}
+
+// TryPushBack tries to add v at the back of bd without waiting. It returns false
+// if bd is full or closed, or there are goroutines waiting to push values.
+func (bd *BlockingDeque[T]) TryPushBack(v T) bool {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if bd.closed || !bd.ready(&bd.putters, 1) {
+		return false
+	}
+	bd.dq.PushBack(v)
+	bd.wake(&bd.takers)
+	return true
+}
+
+// PopFrontWait removes and returns the first value of bd. If bd is empty, it
+// waits until there is a value, ctx is done or bd is closed. It returns ErrClosed
+// if bd is closed and empty, or ctx.Err() if ctx is done before a value is removed.
+func (bd *BlockingDeque[T]) PopFrontWait(ctx context.Context) (T, error) {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if err := bd.wait(ctx, &bd.takers, 1); err != nil {
+		var defVal T
+		return defVal, err
+	}
+	if bd.dq.count == 0 {
+		var defVal T
+		return defVal, ErrClosed
+	}
+	v := bd.dq.PopFront()
+	bd.wake(&bd.putters)
+	return v, nil
+}
+
+// PopBackWait removes and returns the last value of bd. If bd is empty, it
+// waits until there is a value, ctx is done or bd is closed. It returns ErrClosed
+// if bd is closed and empty, or ctx.Err() if ctx is done before a value is removed.
+func (bd *BlockingDeque[T]) PopBackWait(ctx context.Context) (T, error) {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if err := bd.wait(ctx, &bd.takers, 1); err != nil {
+		var defVal T
+		return defVal, err
+	}
+	if bd.dq.count == 0 {
+		var defVal T
+		return defVal, ErrClosed
+	}
+	v := bd.dq.PopBack()
+	bd.wake(&bd.putters)
+	return v, nil
+}
+
+// TryPopFront tries to remove and return the first value of bd without waiting.
+// The return value ok indicates whether a value is removed. It fails if bd is
+// empty or there are goroutines waiting for values.
+func (bd *BlockingDeque[T]) TryPopFront() (_ T, ok bool) {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if !bd.ready(&bd.takers, 1) {
+		var defVal T
+		return defVal, false
+	}
+	v := bd.dq.PopFront()
+	bd.wake(&bd.putters)
+	return v, true
+}
+
+// TryPopBack tries to remove and return the last value of bd without waiting.
+// The return value ok indicates whether a value is removed. It fails if bd is
+// empty or there are goroutines waiting for values.
+func (bd *BlockingDeque[T]) TryPopBack() (_ T, ok bool) {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if !bd.ready(&bd.takers, 1) {
+		var defVal T
+		return defVal, false
+	}
+	v := bd.dq.PopBack()
+	bd.wake(&bd.putters)
+	return v, true
+}
+
+// DequeueManyWait waits until there are at least min values in bd, then removes
+// at most max values from the front of bd and returns them. max <= 0 means no
+// limit, min < 1 is treated as 1, and min is capped to the capacity of bd. Like
+// DequeueManyWithBuffer, it uses buf to store the removed values as long as buf
+// has enough space.
+//
+// After bd is closed, it removes the remaining values even if there are fewer
+// than min of them, and returns ErrClosed once bd is empty. It returns ctx.Err()
+// if ctx is done before any value is removed.
+func (bd *BlockingDeque[T]) DequeueManyWait(ctx context.Context, min, max int, buf []T) ([]T, error) {
+	if min < 1 {
+		min = 1
+	}
+	if bd.capacity > 0 && min > bd.capacity {
+		min = bd.capacity
+	}
+	if max > 0 && min > max {
+		min = max
+	}
+
+	bd.mu.Lock()
+	defer bd.unlock()
+	if err := bd.wait(ctx, &bd.takers, min); err != nil {
+		return nil, err
+	}
+	n := bd.dq.count
+	if n == 0 {
+		return nil, ErrClosed
+	}
+	if !bd.closed {
+		// Leave the values promised to the other waiters.
+		n -= bd.takers.reserved
+	}
+	if max <= 0 || max > n {
+		max = n
+	}
+	buf = bd.dq.DequeueManyWithBuffer(max, buf)
+	bd.wake(&bd.putters)
+	return buf, nil
+}
+
+// Len returns the number of values in bd.
+func (bd *BlockingDeque[T]) Len() int {
+	bd.mu.Lock()
+	defer bd.unlock()
+	return bd.dq.count
+}
+
+// Cap returns the capacity of bd, or 0 if bd has no capacity.
+func (bd *BlockingDeque[T]) Cap() int {
+	return bd.capacity
+}
+
+// Close closes bd and wakes all the waiters. After that, the push methods return
+// ErrClosed, and the pop methods keep removing the remaining values of bd and
+// return ErrClosed once bd is empty. Close can be called more than once.
+func (bd *BlockingDeque[T]) Close() {
+	bd.mu.Lock()
+	defer bd.unlock()
+	if bd.closed {
+		return
+	}
+	bd.closed = true
+	bd.wake(&bd.takers)
+	bd.wake(&bd.putters)
+}
+
+// IsClosed returns whether bd is closed.
+func (bd *BlockingDeque[T]) IsClosed() bool {
+	bd.mu.Lock()
+	defer bd.unlock()
+	return bd.closed
+}
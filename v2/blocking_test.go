@@ -0,0 +1,336 @@
+package deque
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests of BlockingDeque are meant to be run with -race, e.g. by coverage.sh.
+
+func waitForWaiters[T any](t *testing.T, bd *BlockingDeque[T], q *waitQueue, n int) {
+	t.Helper()
+	for i := 0; ; i++ {
+		bd.mu.Lock()
+		m := len(q.waiters)
+		bd.mu.Unlock()
+		if m == n {
+			return
+		}
+		if i == 1000 {
+			t.Fatalf("len(q.waiters) != %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+//gocyclo:ignore
+func TestBlockingDeque_Concurrent(t *testing.T) {
+	const producers, consumers, total = 4, 4, 5000
+	bd := NewBlockingDeque[int](16, WithChunkSize(8))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < total; i++ {
+				v := p*total + i
+				if i%5 == 0 && bd.TryPushBack(v) {
+					continue
+				}
+				if err := bd.PushBackWait(ctx, v); err != nil {
+					t.Error(err)
+					return
+				}
+				if bd.Len() > 16 {
+					t.Error(`bd.Len() > 16`)
+					return
+				}
+			}
+		}(p)
+	}
+
+	results := make([][]int, consumers)
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			var buf []int
+			for {
+				var err error
+				switch c % 3 {
+				case 0:
+					var v int
+					if v, err = bd.PopFrontWait(ctx); err == nil {
+						results[c] = append(results[c], v)
+					}
+				case 1:
+					var v int
+					if v, err = bd.PopBackWait(ctx); err == nil {
+						results[c] = append(results[c], v)
+					}
+				default:
+					if buf, err = bd.DequeueManyWait(ctx, 4, 8, buf); err == nil {
+						if len(buf) == 0 || len(buf) > 8 {
+							t.Errorf("unexpected number of values: %d", len(buf))
+							return
+						}
+						results[c] = append(results[c], buf...)
+					}
+				}
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	bd.Close()
+	cwg.Wait()
+
+	var all []int
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	if len(all) != producers*total {
+		t.Fatalf("unexpected number of values: %d", len(all))
+	}
+	sort.Ints(all)
+	for i, v := range all {
+		if v != i {
+			t.Fatalf("unexpected value: %d", v)
+		}
+	}
+	if bd.takers.reserved != 0 || bd.putters.reserved != 0 {
+		t.Fatal(`bd.takers.reserved != 0 || bd.putters.reserved != 0`)
+	}
+}
+
+func TestBlockingDeque_Fairness(t *testing.T) {
+	const n = 10
+	bd := NewBlockingDeque[int](0)
+	order := make(chan int, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			if _, err := bd.PopFrontWait(context.Background()); err == nil {
+				order <- i
+			}
+		}(i)
+		waitForWaiters(t, bd, &bd.takers, i+1)
+	}
+
+	for i := 0; i < n; i++ {
+		bd.TryPushBack(i)
+		// Only one waiter is woken for each value.
+		bd.mu.Lock()
+		if len(bd.takers.waiters) != n-i-1 {
+			bd.mu.Unlock()
+			t.Fatalf("len(bd.takers.waiters) != %d", n-i-1)
+		}
+		bd.mu.Unlock()
+		if v := <-order; v != i {
+			t.Fatalf("waiter %d is woken before waiter %d", v, i)
+		}
+	}
+	if bd.Len() != 0 {
+		t.Fatal(`bd.Len() != 0`)
+	}
+}
+
+func TestBlockingDeque_Capacity(t *testing.T) {
+	bd := NewBlockingDeque[int](2)
+	ctx := context.Background()
+	if bd.PushBackWait(ctx, 1) != nil || bd.PushBackWait(ctx, 2) != nil {
+		t.Fatal(`bd.PushBackWait should succeed`)
+	}
+	if bd.TryPushBack(3) {
+		t.Fatal(`bd.TryPushBack(3) should return false`)
+	}
+
+	done := make(chan error)
+	for i := 3; i <= 4; i++ {
+		go func(i int) {
+			done <- bd.PushBackWait(ctx, i)
+		}(i)
+		waitForWaiters(t, bd, &bd.putters, i-2)
+	}
+	if v, ok := bd.TryPopFront(); !ok || v != 1 {
+		t.Fatal(`!ok || v != 1`)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	waitForWaiters(t, bd, &bd.putters, 1)
+	if v, err := bd.PopBackWait(ctx); err != nil || v != 3 {
+		t.Fatal(`err != nil || v != 3`)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	checkBufs(nil, bd.dq.Dump(), []int{2, 4}, "", t)
+	if bd.Cap() != 2 {
+		t.Fatal(`bd.Cap() != 2`)
+	}
+
+	// min is capped to the capacity.
+	buf, err := bd.DequeueManyWait(ctx, 10, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBufs(nil, buf, []int{2, 4}, "", t)
+}
+
+func TestBlockingDeque_Cancel(t *testing.T) {
+	bd := NewBlockingDeque[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := bd.PopFrontWait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := bd.DequeueManyWait(ctx, 1, 1, nil); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bd.TryPushBack(1)
+	if err := bd.PushBackWait(ctx, 2); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bd.takers.waiters) != 0 || len(bd.putters.waiters) != 0 {
+		t.Fatal(`len(bd.takers.waiters) != 0 || len(bd.putters.waiters) != 0`)
+	}
+
+	// A waiter giving up passes its place on to the waiters behind it.
+	bd = NewBlockingDeque[int](0)
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		_, err := bd.DequeueManyWait(ctx1, 2, 2, nil)
+		errs <- err
+	}()
+	waitForWaiters(t, bd, &bd.takers, 1)
+	go func() {
+		_, err := bd.PopBackWait(context.Background())
+		errs <- err
+	}()
+	waitForWaiters(t, bd, &bd.takers, 2)
+	bd.TryPushBack(1)
+	cancel1()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil && err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if bd.Len() != 0 || bd.takers.reserved != 0 {
+		t.Fatal(`bd.Len() != 0 || bd.takers.reserved != 0`)
+	}
+}
+
+func TestBlockingDeque_Close(t *testing.T) {
+	bd := NewBlockingDeque[int](0)
+	errs := make(chan error, 3)
+	go func() {
+		_, err := bd.PopFrontWait(context.Background())
+		errs <- err
+	}()
+	go func() {
+		_, err := bd.DequeueManyWait(context.Background(), 3, 0, nil)
+		errs <- err
+	}()
+	waitForWaiters(t, bd, &bd.takers, 2)
+	bd.Close()
+	bd.Close()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != ErrClosed {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !bd.IsClosed() || bd.TryPushBack(1) || bd.PushBackWait(context.Background(), 1) != ErrClosed {
+		t.Fatal(`bd should be closed`)
+	}
+
+	// The remaining values can still be removed after Close.
+	bd = NewBlockingDeque[int](2)
+	bd.TryPushBack(1)
+	bd.TryPushBack(2)
+	go func() {
+		errs <- bd.PushBackWait(context.Background(), 3)
+	}()
+	waitForWaiters(t, bd, &bd.putters, 1)
+	bd.Close()
+	if err := <-errs; err != ErrClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf, err := bd.DequeueManyWait(context.Background(), 5, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBufs(nil, buf, []int{1, 2}, "", t)
+	if _, err := bd.PopBackWait(context.Background()); err != ErrClosed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := bd.TryPopBack(); ok {
+		t.Fatal(`bd.TryPopBack() should return false`)
+	}
+}
+
+func TestBlockingDeque_Evicted(t *testing.T) {
+	bd := NewBlockingDeque[int](0, WithMaxBytes(10, sizeOfInt))
+	errs := make(chan error)
+	go func() {
+		_, err := bd.DequeueManyWait(context.Background(), 2, 2, nil)
+		errs <- err
+	}()
+	waitForWaiters(t, bd, &bd.takers, 1)
+
+	// The waiter is woken for 2 values, but one of them is evicted before it
+	// gets them, so it has to wait again.
+	bd.mu.Lock()
+	bd.dq.PushBack(3)
+	bd.dq.PushBack(3)
+	bd.wake(&bd.takers)
+	bd.dq.PushBack(6)
+	bd.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	waitForWaiters(t, bd, &bd.takers, 1)
+
+	bd.TryPushBack(1)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if bd.Len() != 0 || bd.takers.reserved != 0 {
+		t.Fatal(`bd.Len() != 0 || bd.takers.reserved != 0`)
+	}
+}
+
+func TestBlockingDeque_Callbacks(t *testing.T) {
+	var bd *BlockingDeque[int]
+	var lens []int
+	bd = NewBlockingDeque[int](0, WithWatermarks(2, 0, func() {
+		// The callbacks can call back into bd without a deadlock.
+		lens = append(lens, bd.Len())
+		bd.TryPushBack(100)
+	}, func() {
+		lens = append(lens, -bd.Len())
+	}))
+
+	ctx := context.Background()
+	if bd.PushBackWait(ctx, 1) != nil || !bd.TryPushBack(2) {
+		t.Fatal(`the push methods should succeed`)
+	}
+	checkBufs(nil, lens, []int{2}, "", t)
+	buf, err := bd.DequeueManyWait(ctx, 3, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBufs(nil, buf, []int{1, 2, 100}, "", t)
+	checkBufs(nil, lens, []int{2, 0}, "", t)
+}
@@ -0,0 +1,266 @@
+package deque
+
+// OverflowPolicy decides what a Deque with a max length does when new values
+// are added while it is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest evicts values from the other end of the Deque to make room for
+	// the new values. PushFront, PushFrontSlice, and Insert and InsertSlice at
+	// idx <= 0 evict values from the back, and the other methods evict values
+	// from the front.
+	DropOldest OverflowPolicy = iota
+	// DropNewest drops the new values that do not fit.
+	DropNewest
+	// Reject rejects the new values that do not fit. Unlike DropNewest, the
+	// rejected values are not passed to the OnEvict callback. Use TryPushBack
+	// or TryPushFront to find out whether a value is rejected.
+	Reject
+	// Panic panics if the new values do not fit.
+	Panic
+)
+
+// WithMaxLen sets the max length of a Deque. n <= 0 means no limit, which is
+// the default. When a Deque is full, what happens to new values depends on its
+// overflow policy, which is DropOldest by default.
+func WithMaxLen(n int) Option {
+	return func(holder *optionHolder) {
+		holder.maxLen = n
+	}
+}
+
+// WithOverflowPolicy sets the overflow policy of a Deque with a max length.
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(holder *optionHolder) {
+		holder.policy = p
+	}
+}
+
+// WithOnEvict sets a callback, which is called with every value evicted or
+// dropped because of the max length of a Deque. Do NOT modify the Deque in f.
+// A SyncDeque or a BlockingDeque calls f after releasing its lock, so f may call
+// its methods.
+//
+// Option is not generic, so the type parameter of f cannot be checked at compile
+// time. It must be the same as that of the Deque, e.g. WithOnEvict(func(int) {...})
+// for a Deque[int], otherwise NewDeque panics with an error naming both types.
+func WithOnEvict[T any](f func(T)) Option {
+	return func(holder *optionHolder) {
+		if f != nil {
+			holder.onEvict = f
+		}
+	}
+}
+
+// MaxLen returns the max length of dq, or 0 if dq has no limit.
+func (dq *Deque[T]) MaxLen() int {
+	if dq.maxLen == maxLenUnlimited {
+		return 0
+	}
+	return dq.maxLen
+}
+
+// TryPushBack is similar to PushBack except that it returns whether v is added
+// to dq. It returns false only if v does not fit and the overflow policy of dq is
+// DropNewest or Reject, or if v alone exceeds the byte limit of dq.
+func (dq *Deque[T]) TryPushBack(v T) bool {
+	if dq.count >= dq.limit && !dq.makeRoom(v, false) {
+		return false
+	}
+	dq.pushBack(v)
+	dq.watch()
+	return true
+}
+
+// TryPushFront is similar to PushFront except that it returns whether v is added
+// to dq. It returns false only if v does not fit and the overflow policy of dq is
+// DropNewest or Reject, or if v alone exceeds the byte limit of dq.
+func (dq *Deque[T]) TryPushFront(v T) bool {
+	if dq.count >= dq.limit && !dq.makeRoom(v, true) {
+		return false
+	}
+	dq.pushFront(v)
+	dq.watch()
+	return true
+}
+
+// makeRoom is called when v is about to be added to dq, which may be full. It
+// follows the overflow policy of dq and returns whether v should still be added,
+// in which case the size of v has been counted in dq.bytes.
+func (dq *Deque[T]) makeRoom(v T, front bool) bool {
+	var size int64
+	if dq.sizeOf != nil {
+		size = dq.sizeOf(v)
+	}
+	if dq.count < dq.maxLen && dq.bytes+size <= dq.maxBytes {
+		dq.bytes += size
+		return true
+	}
+
+	switch dq.policy {
+	case DropOldest:
+		if size > dq.maxBytes {
+			if dq.onEvict != nil {
+				dq.onEvict(v)
+			}
+			return false
+		}
+		for dq.count >= dq.maxLen || dq.bytes+size > dq.maxBytes {
+			if front {
+				dq.evictBack(1)
+			} else {
+				dq.evictFront(1)
+			}
+		}
+		dq.bytes += size
+		return true
+	case DropNewest:
+		if dq.onEvict != nil {
+			dq.onEvict(v)
+		}
+		return false
+	case Reject:
+		return false
+	default:
+		panic(errFull)
+	}
+}
+
+// admit is similar to makeRoom except that it works with a list of values, and it
+// returns the values that should still be added.
+func (dq *Deque[T]) admit(vs []T, front bool) []T {
+	if dq.sizeOf != nil {
+		return dq.admitSized(vs, front)
+	}
+	excess := dq.count + len(vs) - dq.maxLen
+	if excess <= 0 {
+		return vs
+	}
+
+	var dropped []T
+	switch dq.policy {
+	case DropOldest:
+		if n := len(vs) - dq.maxLen; n > 0 {
+			excess -= n
+			if front {
+				vs, dropped = vs[:dq.maxLen], vs[dq.maxLen:]
+			} else {
+				dropped, vs = vs[:n], vs[n:]
+			}
+		}
+		if front {
+			dq.evictBack(excess)
+		} else {
+			dq.evictFront(excess)
+		}
+	case DropNewest, Reject:
+		if front {
+			dropped, vs = vs[:excess], vs[excess:]
+		} else {
+			vs, dropped = vs[:len(vs)-excess], vs[len(vs)-excess:]
+		}
+		if dq.policy == Reject {
+			return vs
+		}
+	default:
+		panic(errFull)
+	}
+
+	dq.evictValues(dropped, front)
+	return vs
+}
+
+// shedBack removes the excess values of dq, which has just got n new values at the
+// back, according to the overflow policy of dq.
+func (dq *Deque[T]) shedBack(n int) {
+	if dq.policy == DropOldest {
+		dq.trimFront()
+		return
+	}
+
+	excess := maxInt(dq.count-dq.maxLen, 0)
+	if dq.sizeOf != nil {
+		b := dq.bytes - dq.sizeOfRange(dq.count-excess, dq.count)
+		for b > dq.maxBytes {
+			excess++
+			b -= dq.sizeOf(dq.Peek(dq.count - excess))
+		}
+	}
+	if excess == 0 {
+		return
+	}
+
+	switch dq.policy {
+	case DropNewest:
+		if dq.onEvict != nil {
+			for _, seg := range dq.Segments(dq.count-excess, dq.count) {
+				dq.evictValues(seg, false)
+			}
+		}
+		dq.DiscardBack(excess)
+	case Reject:
+		dq.DiscardBack(excess)
+	default:
+		dq.DiscardBack(n)
+		panic(errFull)
+	}
+}
+
+// trimFront evicts values from the front of dq until it fits in its limits.
+func (dq *Deque[T]) trimFront() {
+	if excess := dq.count - dq.maxLen; excess > 0 {
+		dq.evictFront(excess)
+	}
+	for dq.bytes > dq.maxBytes {
+		dq.evictFront(1)
+	}
+}
+
+// evictValues passes vs to the OnEvict callback of dq, if any, in reverse order
+// if reverse is true.
+func (dq *Deque[T]) evictValues(vs []T, reverse bool) {
+	if dq.onEvict == nil {
+		return
+	}
+	if reverse {
+		for i := len(vs) - 1; i >= 0; i-- {
+			dq.onEvict(vs[i])
+		}
+	} else {
+		for _, v := range vs {
+			dq.onEvict(v)
+		}
+	}
+}
+
+func (dq *Deque[T]) evictFront(n int) {
+	dq.dropFront(dq, n)
+}
+
+func (dq *Deque[T]) evictBack(n int) {
+	dq.dropBack(dq, n)
+}
+
+// dropFront removes n values from the front of src and passes them to the OnEvict
+// callback of dq, if any.
+func (dq *Deque[T]) dropFront(src *Deque[T], n int) {
+	if dq.onEvict == nil {
+		src.DiscardFront(n)
+		return
+	}
+	for i := 0; i < n; i++ {
+		dq.onEvict(src.PopFront())
+	}
+}
+
+// dropBack removes n values from the back of src and passes them to the OnEvict
+// callback of dq, if any.
+func (dq *Deque[T]) dropBack(src *Deque[T], n int) {
+	if dq.onEvict == nil {
+		src.DiscardBack(n)
+		return
+	}
+	for i := 0; i < n; i++ {
+		dq.onEvict(src.PopBack())
+	}
+}
@@ -0,0 +1,335 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// boundedModel mimics a Deque with a max length by adding values one by one.
+type boundedModel struct {
+	a       []int
+	maxLen  int
+	policy  OverflowPolicy
+	evicted []int
+}
+
+func (m *boundedModel) pushBack(v int) bool {
+	if len(m.a) >= m.maxLen {
+		switch m.policy {
+		case DropOldest:
+			m.evicted = append(m.evicted, m.a[0])
+			m.a = m.a[1:]
+		case DropNewest:
+			m.evicted = append(m.evicted, v)
+			return false
+		default:
+			return false
+		}
+	}
+	m.a = append(m.a, v)
+	return true
+}
+
+func (m *boundedModel) pushFront(v int) bool {
+	if len(m.a) >= m.maxLen {
+		switch m.policy {
+		case DropOldest:
+			m.evicted = append(m.evicted, m.a[len(m.a)-1])
+			m.a = m.a[:len(m.a)-1]
+		case DropNewest:
+			m.evicted = append(m.evicted, v)
+			return false
+		default:
+			return false
+		}
+	}
+	m.a = append([]int{v}, m.a...)
+	return true
+}
+
+func (m *boundedModel) pushBackSlice(vs []int) int {
+	var n int
+	for _, v := range vs {
+		if m.pushBack(v) {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *boundedModel) pushFrontSlice(vs []int) int {
+	var n int
+	for i := len(vs) - 1; i >= 0; i-- {
+		if m.pushFront(vs[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *boundedModel) insertSlice(idx int, vs []int) {
+	switch {
+	case idx <= 0:
+		m.pushFrontSlice(vs)
+		return
+	case idx >= len(m.a):
+		m.pushBackSlice(vs)
+		return
+	}
+
+	excess := len(m.a) + len(vs) - m.maxLen
+	if excess > 0 && m.policy != DropOldest {
+		fit := len(vs) - excess
+		if m.policy == DropNewest {
+			m.evicted = append(m.evicted, vs[fit:]...)
+		}
+		vs = vs[:fit]
+	}
+	a := append(append(append([]int(nil), m.a[:idx]...), vs...), m.a[idx:]...)
+	if excess > 0 && m.policy == DropOldest {
+		m.evicted = append(m.evicted, a[:excess]...)
+		a = a[excess:]
+	}
+	m.a = a
+}
+
+func newValues(r *rand.Rand, next *int, max int) []int {
+	vs := make([]int, r.Intn(max+1))
+	for i := range vs {
+		*next++
+		vs[i] = *next
+	}
+	return vs
+}
+
+//gocyclo:ignore
+func TestDeque_MaxLen(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, policy := range []OverflowPolicy{DropOldest, DropNewest, Reject} {
+		for _, maxLen := range []int{1, 2, 7, 8, 9, 20, 50} {
+			m := &boundedModel{maxLen: maxLen, policy: policy}
+			var evicted []int
+			dq := NewDeque[int](WithChunkSize(8), WithMaxLen(maxLen), WithOverflowPolicy(policy),
+				WithOnEvict(func(v int) {
+					evicted = append(evicted, v)
+				}))
+			if dq.MaxLen() != maxLen {
+				t.Fatal(`dq.MaxLen() != maxLen`)
+			}
+
+			var next int
+			for i := 0; i < 3000; i++ {
+				op := r.Intn(14)
+				str := fmt.Sprintf("policy: %d, maxLen: %d, i: %d, op: %d", policy, maxLen, i, op)
+				switch op {
+				case 0:
+					next++
+					dq.PushBack(next)
+					m.pushBack(next)
+				case 1:
+					next++
+					dq.PushFront(next)
+					m.pushFront(next)
+				case 2:
+					next++
+					if dq.TryPushBack(next) != m.pushBack(next) {
+						t.Fatalf("unexpected result of TryPushBack. %s", str)
+					}
+				case 3:
+					next++
+					if dq.TryPushFront(next) != m.pushFront(next) {
+						t.Fatalf("unexpected result of TryPushFront. %s", str)
+					}
+				case 4:
+					vs := newValues(r, &next, maxLen*2)
+					dq.PushBackSlice(vs)
+					m.pushBackSlice(vs)
+				case 5:
+					vs := newValues(r, &next, maxLen*2)
+					dq.PushFrontSlice(vs)
+					m.pushFrontSlice(vs)
+				case 6:
+					next++
+					idx := r.Intn(len(m.a) + 1)
+					dq.Insert(idx, next)
+					m.insertSlice(idx, []int{next})
+				case 7:
+					vs := newValues(r, &next, maxLen*2)
+					idx := r.Intn(len(m.a) + 1)
+					dq.InsertSlice(idx, vs)
+					m.insertSlice(idx, vs)
+				case 8:
+					vs := newValues(r, &next, maxLen*2)
+					segs := dq.ReserveBack(len(vs))
+					var k int
+					for _, seg := range segs {
+						k += copy(seg, vs[k:])
+					}
+					dq.CommitBack(len(vs))
+					m.pushBackSlice(vs)
+				case 9:
+					vs := newValues(r, &next, maxLen*2)
+					src := NewDequeFromSlice(vs, WithChunkSize(8+r.Intn(2)*8))
+					dq.AppendDeque(src)
+					n := m.pushBackSlice(vs)
+					if policy == Reject {
+						checkTransfer(t, src, vs[n:], str)
+					} else {
+						checkTransfer(t, src, nil, str)
+					}
+				case 10:
+					vs := newValues(r, &next, maxLen*2)
+					src := NewDequeFromSlice(vs, WithChunkSize(8+r.Intn(2)*8))
+					dq.PrependDeque(src)
+					n := m.pushFrontSlice(vs)
+					if policy == Reject {
+						checkTransfer(t, src, vs[:len(vs)-n], str)
+					} else {
+						checkTransfer(t, src, nil, str)
+					}
+				case 11:
+					vs := newValues(r, &next, maxLen*2)
+					src := NewDequeFromSlice(vs, WithChunkSize(8+r.Intn(2)*8))
+					k := r.Intn(len(vs) + 1)
+					n := m.pushBackSlice(vs[:k])
+					if moved := src.TransferFrontTo(dq, k); moved != n {
+						t.Fatalf("unexpected result of TransferFrontTo: %d. %s", moved, str)
+					}
+					if policy == Reject {
+						checkTransfer(t, src, vs[n:], str)
+					} else {
+						checkTransfer(t, src, vs[k:], str)
+					}
+				case 12:
+					if len(m.a) > 0 {
+						dq.PopFront()
+						m.a = m.a[1:]
+					}
+				case 13:
+					if len(m.a) > 0 {
+						dq.PopBack()
+						m.a = m.a[:len(m.a)-1]
+					}
+				}
+
+				invariant(t, dq, skipChunkMerge())
+				if dq.Len() != len(m.a) {
+					t.Fatalf("dq.Len() != len(m.a). %s", str)
+				}
+				checkValues(t, dq, m.a...)
+				if len(evicted) != len(m.evicted) {
+					t.Fatalf("len(evicted) != len(m.evicted). %s", str)
+				}
+				for j, v := range m.evicted {
+					if evicted[j] != v {
+						t.Fatalf("evicted[j] != v. %s", str)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDeque_MaxLenPanic(t *testing.T) {
+	mustPanic := func(f func(dq *Deque[int])) {
+		t.Helper()
+		dq := NewDequeFromSlice([]int{1, 2, 3}, WithMaxLen(3), WithOverflowPolicy(Panic))
+		defer func() {
+			t.Helper()
+			if r := recover(); r != errFull {
+				t.Fatalf("unexpected panic: %v", r)
+			}
+			checkTransfer(t, dq, []int{1, 2, 3}, "")
+		}()
+		f(dq)
+	}
+
+	mustPanic(func(dq *Deque[int]) { dq.PushBack(4) })
+	mustPanic(func(dq *Deque[int]) { dq.PushFront(4) })
+	mustPanic(func(dq *Deque[int]) { dq.TryPushBack(4) })
+	mustPanic(func(dq *Deque[int]) { dq.Insert(1, 4) })
+	mustPanic(func(dq *Deque[int]) { dq.PushBackSlice([]int{4}) })
+	mustPanic(func(dq *Deque[int]) { dq.PushFrontSlice([]int{4}) })
+	mustPanic(func(dq *Deque[int]) { dq.InsertSlice(1, []int{4}) })
+	mustPanic(func(dq *Deque[int]) { dq.AppendDeque(NewDequeFromSlice([]int{4})) })
+	mustPanic(func(dq *Deque[int]) { dq.PrependDeque(NewDequeFromSlice([]int{4})) })
+	mustPanic(func(dq *Deque[int]) { dq.CursorAt(1).InsertBefore(4) })
+	mustPanic(func(dq *Deque[int]) {
+		dq.ReserveBack(1)[0][0] = 4
+		dq.CommitBack(1)
+	})
+
+	dq := NewDequeFromSlice([]int{1, 2}, WithMaxLen(3), WithOverflowPolicy(Panic))
+	dq.PushBackSlice([]int{3})
+	checkTransfer(t, dq, []int{1, 2, 3}, "")
+}
+
+func TestDeque_MaxLenCursor(t *testing.T) {
+	var evicted []int
+	onEvict := WithOnEvict(func(v int) {
+		evicted = append(evicted, v)
+	})
+
+	dq := NewDequeFromSlice([]int{1, 2, 3, 4}, WithChunkSize(8), WithMaxLen(4), onEvict)
+	cur := dq.CursorAt(2)
+	cur.InsertBefore(5)
+	if cur.Index() != 2 || cur.Value() != 3 {
+		t.Fatal(`cur.Index() != 2 || cur.Value() != 3`)
+	}
+	cur.InsertAfter(6)
+	if cur.Index() != 1 || cur.Value() != 3 {
+		t.Fatal(`cur.Index() != 1 || cur.Value() != 3`)
+	}
+	checkTransfer(t, dq, []int{5, 3, 6, 4}, "")
+	checkBufs(nil, evicted, []int{1, 2}, "", t)
+
+	cur = dq.CursorAt(0)
+	cur.InsertAfter(7)
+	if cur.Index() != -1 || cur.Valid() {
+		t.Fatal(`cur.Index() != -1 || cur.Valid()`)
+	}
+	checkTransfer(t, dq, []int{7, 3, 6, 4}, "")
+
+	cur = dq.CursorAt(4)
+	cur.InsertBefore(8)
+	if cur.Index() != 4 || cur.Valid() {
+		t.Fatal(`cur.Index() != 4 || cur.Valid()`)
+	}
+	checkTransfer(t, dq, []int{3, 6, 4, 8}, "")
+
+	cur = dq.CursorAt(0)
+	cur.InsertBefore(9)
+	if cur.Index() != 1 || cur.Value() != 3 {
+		t.Fatal(`cur.Index() != 1 || cur.Value() != 3`)
+	}
+	checkTransfer(t, dq, []int{9, 3, 6, 4}, "")
+	checkBufs(nil, evicted, []int{1, 2, 5, 7, 8}, "", t)
+
+	dq = NewDequeFromSlice([]int{1, 2}, WithMaxLen(2), WithOverflowPolicy(Reject))
+	cur = dq.CursorAt(1)
+	cur.InsertBefore(3)
+	cur.InsertAfter(4)
+	if cur.Index() != 1 || cur.Value() != 2 {
+		t.Fatal(`cur.Index() != 1 || cur.Value() != 2`)
+	}
+	checkTransfer(t, dq, []int{1, 2}, "")
+}
+
+func TestWithOnEvict(t *testing.T) {
+	dq := NewDeque[int]()
+	if dq.MaxLen() != 0 {
+		t.Fatal(`dq.MaxLen() != 0`)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal(`NewDeque should panic`)
+		}
+		if err := r.(error).Error(); err != "the callback of WithOnEvict should be func(int), not func(string)" {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}()
+	NewDeque[int](WithMaxLen(1), WithOnEvict(func(string) {}))
+}
@@ -0,0 +1,259 @@
+package deque
+
+// WithMaxBytes sets the byte limit of a Deque. sizeOf reports the size of a value,
+// and the Deque keeps a running total of the sizes of all its values, which is
+// reported by Bytes. limit <= 0 means no limit, in which case the Deque only tracks
+// its byte usage.
+//
+// Like WithOnEvict, the type parameter of sizeOf cannot be checked at compile time.
+// It must be the same as that of the Deque, otherwise NewDeque panics with an error
+// naming both types.
+//
+// When new values do not fit in the byte limit, what happens depends on the
+// overflow policy of the Deque, in the same way as WithMaxLen. A single value
+// larger than the limit is never added: it is passed to the OnEvict callback
+// with DropOldest or DropNewest.
+//
+// The byte usage is updated by every method adding, removing or replacing values.
+// Writing to the values through At, FrontPtr, BackPtr or Segments is not tracked.
+// The size of a value must not change while it is in the Deque.
+func WithMaxBytes[T any](limit int64, sizeOf func(T) int64) Option {
+	return func(holder *optionHolder) {
+		if sizeOf != nil {
+			holder.maxBytes = limit
+			holder.sizeOf = sizeOf
+		}
+	}
+}
+
+// Bytes returns the total size of all the values in dq, or 0 if dq is not created
+// with WithMaxBytes.
+func (dq *Deque[T]) Bytes() int64 {
+	return dq.bytes
+}
+
+func (dq *Deque[T]) sizeOfSlice(vs []T) int64 {
+	if dq.sizeOf == nil {
+		return 0
+	}
+	var total int64
+	for _, v := range vs {
+		total += dq.sizeOf(v)
+	}
+	return total
+}
+
+func (dq *Deque[T]) sizeOfRange(from, to int) int64 {
+	var total int64
+	for _, seg := range dq.Segments(from, to) {
+		total += dq.sizeOfSlice(seg)
+	}
+	return total
+}
+
+// fitsRange reports whether the values of src in [from, to) fit in dq without
+// evicting anything.
+func (dq *Deque[T]) fitsRange(src *Deque[T], from, to int) bool {
+	if dq.count+to-from > dq.maxLen {
+		return false
+	}
+	b := dq.bytes
+	for _, seg := range src.Segments(from, to) {
+		b += dq.sizeOfSlice(seg)
+	}
+	return b <= dq.maxBytes
+}
+
+// admitSized is the same as admit except that dq has a byte limit. The values are
+// handled as if they were added one by one.
+func (dq *Deque[T]) admitSized(vs []T, front bool) []T {
+	total := dq.sizeOfSlice(vs)
+	if dq.count+len(vs) <= dq.maxLen && dq.bytes+total <= dq.maxBytes {
+		dq.bytes += total
+		return vs
+	}
+
+	switch dq.policy {
+	case DropOldest:
+		dq.pushEach(vs, front)
+		return nil
+	case DropNewest, Reject:
+		return dq.keepFitting(vs, front)
+	default:
+		panic(errFull)
+	}
+}
+
+// pushEach adds the values in vs to dq one by one, each evicting the oldest
+// values as needed.
+func (dq *Deque[T]) pushEach(vs []T, front bool) {
+	if front {
+		for i := len(vs) - 1; i >= 0; i-- {
+			dq.PushFront(vs[i])
+		}
+	} else {
+		for _, v := range vs {
+			dq.PushBack(v)
+		}
+	}
+}
+
+// keepFitting returns the values in vs which still fit in dq when they are added
+// one by one, and counts their sizes in dq.bytes. With DropNewest, the other
+// values are passed to the OnEvict callback of dq, if any.
+func (dq *Deque[T]) keepFitting(vs []T, front bool) []T {
+	kept := make([]T, 0, len(vs))
+	count, b := dq.count, dq.bytes
+	for i := range vs {
+		v := vs[i]
+		if front {
+			v = vs[len(vs)-1-i]
+		}
+		if size := dq.sizeOf(v); count < dq.maxLen && b+size <= dq.maxBytes {
+			kept = append(kept, v)
+			count++
+			b += size
+		} else if dq.policy == DropNewest && dq.onEvict != nil {
+			dq.onEvict(v)
+		}
+	}
+	if front {
+		for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+			kept[i], kept[j] = kept[j], kept[i]
+		}
+	}
+	dq.bytes = b
+	return kept
+}
+
+// admitAt is the same as admitSized except that vs is about to be inserted in the
+// middle of dq. With DropOldest, it only drops the values larger than the byte
+// limit, and the caller trims the front of dq after the insertion.
+func (dq *Deque[T]) admitAt(vs []T) []T {
+	if dq.policy != DropOldest {
+		return dq.admitSized(vs, false)
+	}
+
+	var kept []T
+	var total int64
+	for i, v := range vs {
+		size := dq.sizeOf(v)
+		if size > dq.maxBytes {
+			if kept == nil {
+				kept = append(make([]T, 0, len(vs)), vs[:i]...)
+			}
+			if dq.onEvict != nil {
+				dq.onEvict(v)
+			}
+			continue
+		}
+		if kept != nil {
+			kept = append(kept, v)
+		}
+		total += size
+	}
+	if kept != nil {
+		vs = kept
+	}
+	dq.bytes += total
+	return vs
+}
+
+// insertSized inserts v at idx, which is neither the front nor the back of dq,
+// and returns whether v is inserted. dq has a byte limit. With DropOldest, values
+// are evicted from the front of dq after the insertion, which may include v.
+func (dq *Deque[T]) insertSized(idx int, v T) bool {
+	size := dq.sizeOf(v)
+	if dq.count >= dq.maxLen || dq.bytes+size > dq.maxBytes {
+		switch dq.policy {
+		case DropOldest:
+			if size <= dq.maxBytes {
+				break
+			}
+			fallthrough
+		case DropNewest:
+			if dq.onEvict != nil {
+				dq.onEvict(v)
+			}
+			return false
+		case Reject:
+			return false
+		default:
+			panic(errFull)
+		}
+	}
+
+	j, k := dq.locate(idx)
+	dq.insertAt(j, k, v)
+	dq.bytes += size
+	dq.trimFront()
+	return true
+}
+
+// replaceAt replaces the value at data[k] of chunk j with v, and returns the number
+// of the values evicted from the front of dq. dq has a byte limit.
+func (dq *Deque[T]) replaceAt(j, k int, v T) int {
+	p := &dq.chunks[j].data[k]
+	size := dq.sizeOf(v)
+	delta := size - dq.sizeOf(*p)
+	if dq.bytes+delta > dq.maxBytes {
+		switch dq.policy {
+		case DropOldest:
+			if size <= dq.maxBytes {
+				break
+			}
+			fallthrough
+		case DropNewest:
+			if dq.onEvict != nil {
+				dq.onEvict(v)
+			}
+			return 0
+		case Reject:
+			return 0
+		default:
+			panic(errFull)
+		}
+	}
+
+	*p = v
+	dq.bytes += delta
+	n := dq.count
+	dq.trimFront()
+	return n - dq.count
+}
+
+// transferEach is the same as TransferFrontTo except that dst has a byte limit,
+// so the values are moved one by one.
+func (dq *Deque[T]) transferEach(dst *Deque[T], n int) int {
+	if dst.policy == Panic && !dst.fitsRange(dq, 0, n) {
+		panic(errFull)
+	}
+
+	var moved int
+	for i := 0; i < n; i++ {
+		v := dq.PopFront()
+		if dst.TryPushBack(v) {
+			moved++
+		} else if dst.policy == Reject {
+			dq.PushFront(v)
+			break
+		}
+	}
+	return moved
+}
+
+// prependEach is the same as PrependDeque except that dq has a byte limit, so
+// the values are moved one by one.
+func (dq *Deque[T]) prependEach(src *Deque[T]) {
+	if dq.policy == Panic && !dq.fitsRange(src, 0, src.count) {
+		panic(errFull)
+	}
+
+	for src.count > 0 {
+		v := src.PopBack()
+		if !dq.TryPushFront(v) && dq.policy == Reject {
+			src.PushBack(v)
+			break
+		}
+	}
+}
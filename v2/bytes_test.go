@@ -0,0 +1,422 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func sizeOfInt(v int) int64 {
+	return int64(v%7 + 1)
+}
+
+// sizedModel mimics a Deque with a byte limit by adding values one by one.
+type sizedModel struct {
+	a        []int
+	maxLen   int
+	maxBytes int64
+	policy   OverflowPolicy
+	evicted  []int
+}
+
+func (m *sizedModel) bytes() int64 {
+	var total int64
+	for _, v := range m.a {
+		total += sizeOfInt(v)
+	}
+	return total
+}
+
+func (m *sizedModel) full(size int64) bool {
+	return len(m.a) >= m.maxLen || m.bytes()+size > m.maxBytes
+}
+
+func (m *sizedModel) trimFront() {
+	for len(m.a) > m.maxLen || m.bytes() > m.maxBytes {
+		m.evicted = append(m.evicted, m.a[0])
+		m.a = m.a[1:]
+	}
+}
+
+func (m *sizedModel) push(v int, front bool) bool {
+	size := sizeOfInt(v)
+	if m.full(size) {
+		switch {
+		case m.policy == DropOldest && size <= m.maxBytes:
+			for m.full(size) {
+				if front {
+					m.evicted = append(m.evicted, m.a[len(m.a)-1])
+					m.a = m.a[:len(m.a)-1]
+				} else {
+					m.evicted = append(m.evicted, m.a[0])
+					m.a = m.a[1:]
+				}
+			}
+		case m.policy == Reject:
+			return false
+		default:
+			m.evicted = append(m.evicted, v)
+			return false
+		}
+	}
+	if front {
+		m.a = append([]int{v}, m.a...)
+	} else {
+		m.a = append(m.a, v)
+	}
+	return true
+}
+
+func (m *sizedModel) pushBackSlice(vs []int) {
+	for _, v := range vs {
+		m.push(v, false)
+	}
+}
+
+func (m *sizedModel) pushFrontSlice(vs []int) {
+	for i := len(vs) - 1; i >= 0; i-- {
+		m.push(vs[i], true)
+	}
+}
+
+func (m *sizedModel) insertSlice(idx int, vs []int) {
+	switch {
+	case idx <= 0:
+		m.pushFrontSlice(vs)
+		return
+	case idx >= len(m.a):
+		m.pushBackSlice(vs)
+		return
+	}
+
+	var kept []int
+	count, b := len(m.a), m.bytes()
+	for _, v := range vs {
+		size := sizeOfInt(v)
+		switch {
+		case m.policy == DropOldest && size <= m.maxBytes:
+		case m.policy == DropOldest:
+			m.evicted = append(m.evicted, v)
+			continue
+		case count >= m.maxLen || b+size > m.maxBytes:
+			if m.policy == DropNewest {
+				m.evicted = append(m.evicted, v)
+			}
+			continue
+		}
+		kept = append(kept, v)
+		count++
+		b += size
+	}
+	m.a = append(append(append([]int(nil), m.a[:idx]...), kept...), m.a[idx:]...)
+	m.trimFront()
+}
+
+func (m *sizedModel) commitBack(vs []int) {
+	m.a = append(m.a, vs...)
+	if m.policy == DropOldest {
+		m.trimFront()
+		return
+	}
+	n := len(m.a)
+	for len(m.a) > m.maxLen || m.bytes() > m.maxBytes {
+		m.a = m.a[:len(m.a)-1]
+	}
+	if m.policy == DropNewest {
+		m.evicted = append(m.evicted, m.a[len(m.a):n]...)
+	}
+}
+
+func (m *sizedModel) replace(idx int, v int) {
+	size := sizeOfInt(v)
+	if m.bytes()-sizeOfInt(m.a[idx])+size > m.maxBytes {
+		switch {
+		case m.policy == DropOldest && size <= m.maxBytes:
+		case m.policy == Reject:
+			return
+		default:
+			m.evicted = append(m.evicted, v)
+			return
+		}
+	}
+	m.a[idx] = v
+	m.trimFront()
+}
+
+//gocyclo:ignore
+func TestDeque_MaxBytes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, policy := range []OverflowPolicy{DropOldest, DropNewest, Reject} {
+		for _, maxLen := range []int{0, 8} {
+			for _, maxBytes := range []int64{5, 12, 40, 200} {
+				m := &sizedModel{maxLen: maxLenUnlimited, maxBytes: maxBytes, policy: policy}
+				if maxLen > 0 {
+					m.maxLen = maxLen
+				}
+				var evicted []int
+				dq := NewDeque[int](WithChunkSize(8), WithMaxLen(maxLen), WithMaxBytes(maxBytes, sizeOfInt),
+					WithOverflowPolicy(policy), WithOnEvict(func(v int) {
+						evicted = append(evicted, v)
+					}))
+
+				var next int
+				for i := 0; i < 2000; i++ {
+					op := r.Intn(18)
+					str := fmt.Sprintf("policy: %d, maxLen: %d, maxBytes: %d, i: %d, op: %d", policy, maxLen, maxBytes, i, op)
+					switch op {
+					case 0:
+						next++
+						dq.PushBack(next)
+						m.push(next, false)
+					case 1:
+						next++
+						dq.PushFront(next)
+						m.push(next, true)
+					case 2:
+						next++
+						if dq.TryPushBack(next) != m.push(next, false) {
+							t.Fatalf("unexpected result of TryPushBack. %s", str)
+						}
+					case 3:
+						next++
+						if dq.TryPushFront(next) != m.push(next, true) {
+							t.Fatalf("unexpected result of TryPushFront. %s", str)
+						}
+					case 4:
+						vs := newValues(r, &next, 10)
+						dq.PushBackSlice(vs)
+						m.pushBackSlice(vs)
+					case 5:
+						vs := newValues(r, &next, 10)
+						dq.PushFrontSlice(vs)
+						m.pushFrontSlice(vs)
+					case 6:
+						next++
+						idx := r.Intn(len(m.a) + 1)
+						dq.Insert(idx, next)
+						m.insertSlice(idx, []int{next})
+					case 7:
+						vs := newValues(r, &next, 10)
+						idx := r.Intn(len(m.a) + 1)
+						dq.InsertSlice(idx, vs)
+						m.insertSlice(idx, vs)
+					case 8:
+						vs := newValues(r, &next, 10)
+						segs := dq.ReserveBack(len(vs))
+						var k int
+						for _, seg := range segs {
+							k += copy(seg, vs[k:])
+						}
+						dq.CommitBack(len(vs))
+						m.commitBack(vs)
+					case 9:
+						if len(m.a) > 0 {
+							next++
+							idx := r.Intn(len(m.a))
+							dq.Replace(idx, next)
+							m.replace(idx, next)
+						}
+					case 10:
+						vs := newValues(r, &next, 10)
+						src := NewDequeFromSlice(vs, WithChunkSize(8))
+						moved := src.TransferFrontTo(dq, len(vs))
+						var n, k int
+						for ; k < len(vs); k++ {
+							if m.push(vs[k], false) {
+								n++
+							} else if policy == Reject {
+								break
+							}
+						}
+						if moved != n {
+							t.Fatalf("unexpected result of TransferFrontTo: %d. %s", moved, str)
+						}
+						checkTransfer(t, src, vs[k:], str)
+					case 11:
+						vs := newValues(r, &next, 10)
+						src := NewDequeFromSlice(vs, WithChunkSize(8), WithMaxBytes(0, sizeOfInt))
+						dq.PrependDeque(src)
+						k := len(vs)
+						for ; k > 0; k-- {
+							if !m.push(vs[k-1], true) && policy == Reject {
+								break
+							}
+						}
+						checkTransfer(t, src, vs[:k], str)
+						if src.Bytes() != (&sizedModel{a: vs[:k]}).bytes() {
+							t.Fatalf("unexpected src.Bytes(): %d. %s", src.Bytes(), str)
+						}
+					case 12:
+						if len(m.a) > 0 {
+							dq.PopFront()
+							m.a = m.a[1:]
+						}
+					case 13:
+						if len(m.a) > 0 {
+							dq.PopBack()
+							m.a = m.a[:len(m.a)-1]
+						}
+					case 14:
+						from := r.Intn(len(m.a) + 1)
+						to := from + r.Intn(len(m.a)-from+1)
+						dq.RemoveRange(from, to)
+						m.a = append(m.a[:from:from], m.a[to:]...)
+					case 15:
+						if len(m.a) > 0 {
+							idx := r.Intn(len(m.a))
+							dq.Remove(idx)
+							m.a = append(m.a[:idx:idx], m.a[idx+1:]...)
+						}
+					case 16:
+						n := 1 + r.Intn(3)
+						dq.DiscardFront(n)
+						dq.DiscardBack(n)
+						dq.DequeueMany(n)
+						dq.PopBackMany(n)
+						for j := 0; j < 2; j++ {
+							m.a = m.a[minInt(n, len(m.a)):]
+							m.a = m.a[:len(m.a)-minInt(n, len(m.a))]
+						}
+					case 17:
+						dq.RemoveFunc(func(v int) bool { return v%5 == 0 })
+						var a []int
+						for _, v := range m.a {
+							if v%5 != 0 {
+								a = append(a, v)
+							}
+						}
+						m.a = a
+					}
+
+					invariant(t, dq, skipChunkMerge())
+					if dq.Len() != len(m.a) {
+						t.Fatalf("dq.Len() != len(m.a). %s", str)
+					}
+					checkValues(t, dq, m.a...)
+					if dq.Bytes() != m.bytes() {
+						t.Fatalf("dq.Bytes() != m.bytes(). %s", str)
+					}
+					checkBufs(nil, evicted, m.evicted, str, t)
+				}
+
+				idx := r.Intn(dq.Len() + 1)
+				nd := dq.SplitAt(idx)
+				if dq.Bytes() != (&sizedModel{a: m.a[:idx]}).bytes() {
+					t.Fatal(`dq.Bytes() != (&sizedModel{a: m.a[:idx]}).bytes()`)
+				}
+				if nd.Bytes() != (&sizedModel{a: m.a[idx:]}).bytes() {
+					t.Fatal(`nd.Bytes() != (&sizedModel{a: m.a[idx:]}).bytes()`)
+				}
+				if c := nd.CloneFunc(func(v int) int { return v }); c.Bytes() != nd.Bytes() {
+					t.Fatal(`c.Bytes() != nd.Bytes()`)
+				}
+				nd.Clear()
+				if nd.Bytes() != 0 {
+					t.Fatal(`nd.Bytes() != 0`)
+				}
+			}
+		}
+	}
+}
+
+func TestDeque_MaxBytesPanic(t *testing.T) {
+	mustPanic := func(f func(dq *Deque[int])) {
+		t.Helper()
+		dq := NewDequeFromSlice([]int{1, 2, 3}, WithMaxBytes(10, sizeOfInt), WithOverflowPolicy(Panic))
+		defer func() {
+			t.Helper()
+			if r := recover(); r != errFull {
+				t.Fatalf("unexpected panic: %v", r)
+			}
+			checkTransfer(t, dq, []int{1, 2, 3}, "")
+			if dq.Bytes() != 9 {
+				t.Fatal(`dq.Bytes() != 9`)
+			}
+		}()
+		f(dq)
+	}
+
+	mustPanic(func(dq *Deque[int]) { dq.PushBack(1) })
+	mustPanic(func(dq *Deque[int]) { dq.PushFront(1) })
+	mustPanic(func(dq *Deque[int]) { dq.Insert(1, 1) })
+	mustPanic(func(dq *Deque[int]) { dq.Replace(1, 6) })
+	mustPanic(func(dq *Deque[int]) { dq.PushBackSlice([]int{0, 0}) })
+	mustPanic(func(dq *Deque[int]) { dq.InsertSlice(1, []int{0, 0}) })
+	mustPanic(func(dq *Deque[int]) { dq.AppendDeque(NewDequeFromSlice([]int{0, 0})) })
+	mustPanic(func(dq *Deque[int]) { dq.PrependDeque(NewDequeFromSlice([]int{0, 0})) })
+	mustPanic(func(dq *Deque[int]) { dq.CursorAt(1).Set(6) })
+	mustPanic(func(dq *Deque[int]) {
+		segs := dq.ReserveBack(2)
+		segs[0][0], segs[0][1] = 0, 0
+		dq.CommitBack(2)
+	})
+
+	dq := NewDequeFromSlice([]int{1, 2, 3}, WithMaxBytes(10, sizeOfInt), WithOverflowPolicy(Panic))
+	dq.PushBack(0)
+	if dq.Bytes() != 10 {
+		t.Fatal(`dq.Bytes() != 10`)
+	}
+}
+
+func TestDeque_MaxBytesCursor(t *testing.T) {
+	var evicted []int
+	dq := NewDequeFromSlice([]int{1, 2, 3, 4}, WithChunkSize(8), WithMaxBytes(16, sizeOfInt),
+		WithOnEvict(func(v int) {
+			evicted = append(evicted, v)
+		}))
+	cur := dq.CursorAt(2)
+	cur.InsertBefore(5)
+	if cur.Index() != 1 || cur.Value() != 3 {
+		t.Fatal(`cur.Index() != 1 || cur.Value() != 3`)
+	}
+	checkTransfer(t, dq, []int{5, 3, 4}, "")
+	cur.InsertAfter(2)
+	if cur.Index() != 0 || cur.Value() != 3 {
+		t.Fatal(`cur.Index() != 0 || cur.Value() != 3`)
+	}
+	checkTransfer(t, dq, []int{3, 2, 4}, "")
+
+	dq.PushBack(1)
+	cur.Set(6)
+	if cur.Index() != -1 || cur.Valid() {
+		t.Fatal(`cur.Index() != -1 || cur.Valid()`)
+	}
+	checkTransfer(t, dq, []int{2, 4, 1}, "")
+
+	cur = dq.CursorAt(1)
+	cur.Set(20)
+	cur.InsertBefore(13)
+	if cur.Index() != 1 || cur.Value() != 20 {
+		t.Fatal(`cur.Index() != 1 || cur.Value() != 20`)
+	}
+	checkTransfer(t, dq, []int{13, 20, 1}, "")
+	checkBufs(nil, evicted, []int{1, 2, 5, 6, 2}, "", t)
+	if dq.Bytes() != 16 {
+		t.Fatal(`dq.Bytes() != 16`)
+	}
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	dq := NewDequeFromSlice([]int{1, 2, 3}, WithMaxBytes(0, sizeOfInt))
+	if dq.Bytes() != 9 {
+		t.Fatal(`dq.Bytes() != 9`)
+	}
+	dq.PushBackSlice(make([]int, 100))
+	if dq.Bytes() != 109 || dq.Len() != 103 {
+		t.Fatal(`dq.Bytes() != 109 || dq.Len() != 103`)
+	}
+	if NewDequeFromSlice([]int{1, 2, 3}).Bytes() != 0 {
+		t.Fatal(`NewDequeFromSlice([]int{1, 2, 3}).Bytes() != 0`)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal(`NewDeque should panic`)
+		}
+		if err := r.(error).Error(); err != "the size function of WithMaxBytes should be func(int) int64, not func(string) int64" {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}()
+	NewDeque[int](WithMaxBytes(1, func(string) int64 { return 0 }))
+}
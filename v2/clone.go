@@ -0,0 +1,41 @@
+package deque
+
+// Clone returns a copy of dq. The copy has the same chunk size as dq, and its
+// chunks are laid out in the same way as those of dq.
+func (dq *Deque[T]) Clone() *Deque[T] {
+	return dq.CloneFunc(nil)
+}
+
+// CloneFunc is similar to Clone except that every value is copied by calling
+// copyElem, which is useful when the values need to be deep copied. If copyElem
+// is nil, the values are copied by assignment.
+func (dq *Deque[T]) CloneFunc(copyElem func(T) T) *Deque[T] {
+	nd := dq.newSibling()
+	nd.chunkPitch = make([]*chunk[T], len(dq.chunkPitch))
+	nd.sFree = dq.sFree
+	nd.eFree = dq.eFree
+	nd.gapLo, nd.gapHi = dq.gapLo, dq.gapHi
+	nd.chunks = nd.chunkPitch[nd.sFree : len(nd.chunkPitch)-nd.eFree]
+	nd.count = dq.count
+	nd.bytes = dq.bytes
+
+	for i, c := range dq.chunks {
+		nc := nd.chunkPool.Get().(*chunk[T])
+		nc.s, nc.e, nc.base = c.s, c.e, c.base
+		if copyElem == nil {
+			copy(nc.data[c.s:c.e], c.data[c.s:c.e])
+		} else {
+			for k := c.s; k < c.e; k++ {
+				nc.data[k] = copyElem(c.data[k])
+			}
+		}
+		nd.chunks[i] = nc
+	}
+	if copyElem != nil && nd.sizeOf != nil {
+		nd.bytes = 0
+		for _, c := range nd.chunks {
+			nd.bytes += nd.sizeOfSlice(c.data[c.s:c.e])
+		}
+	}
+	return nd
+}
@@ -0,0 +1,62 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestDeque_Clone(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		str := fmt.Sprintf("total: %d", total)
+		nd := dq.Clone()
+		invariant(t, nd, skipChunkMerge())
+		if nd.chunkSize != dq.chunkSize {
+			t.Fatalf("nd.chunkSize != dq.chunkSize. %s", str)
+		}
+		if nd.sFree != dq.sFree || nd.eFree != dq.eFree || len(nd.chunkPitch) != len(dq.chunkPitch) {
+			t.Fatalf("nd should have the same pitch layout as dq. %s", str)
+		}
+		for i, c := range nd.chunks {
+			if c == dq.chunks[i] || c.s != dq.chunks[i].s || c.e != dq.chunks[i].e {
+				t.Fatalf("nd should have the same chunk layout as dq. %s", str)
+			}
+		}
+		if nd.Len() != len(a) {
+			t.Fatalf("nd.Len() != len(a). %s", str)
+		}
+		checkValues(t, nd, a...)
+
+		for i := 0; i < nd.Len(); i++ {
+			nd.Replace(i, -1)
+		}
+		nd.PushBack(-2)
+		nd.PushFront(-3)
+		invariant(t, nd, skipChunkMerge())
+		checkValues(t, dq, a...)
+		if dq.Len() != len(a) {
+			t.Fatalf("dq.Len() != len(a). %s", str)
+		}
+	}
+}
+
+func TestDeque_CloneFunc(t *testing.T) {
+	dq := NewDeque[[]int](WithChunkSize(8))
+	for i := 0; i < 20; i++ {
+		dq.PushBack([]int{i})
+	}
+	nd := dq.CloneFunc(func(v []int) []int {
+		return append([]int(nil), v...)
+	})
+	if nd.Len() != dq.Len() {
+		t.Fatal(`nd.Len() != dq.Len()`)
+	}
+	for i := 0; i < nd.Len(); i++ {
+		nd.Peek(i)[0] = -1
+		if dq.Peek(i)[0] != i {
+			t.Fatal(`dq.Peek(i)[0] != i`)
+		}
+	}
+}
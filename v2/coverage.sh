@@ -32,4 +32,4 @@ function printUsage() {
     $colorful && tput setaf 7
 }
 
-go test -cover -coverprofile=c.out -v "$@" && go tool cover -html=c.out
+go test -race -cover -coverprofile=c.out -v "$@" && go tool cover -html=c.out
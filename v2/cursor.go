@@ -0,0 +1,263 @@
+package deque
+
+import (
+	"fmt"
+)
+
+// Cursor represents a position in a Deque. A Cursor either points at a value of
+// the Deque, or sits at one of the two ends: before the first value (Index() == -1)
+// or after the last value (Index() == Len()).
+//
+// A Cursor stays valid across its own mutations, but any change made to the Deque
+// by other means, including another Cursor, invalidates it.
+type Cursor[T any] struct {
+	dq  *Deque[T]
+	idx int
+	j   int
+	k   int
+}
+
+// CursorAt returns a Cursor pointing at the value at idx. If idx equals dq.Len(),
+// the Cursor sits after the last value. It panics if idx is out of range.
+func (dq *Deque[T]) CursorAt(idx int) *Cursor[T] {
+	if idx < 0 || idx > dq.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+
+	cur := &Cursor[T]{
+		dq:  dq,
+		idx: idx,
+	}
+	if idx < dq.count {
+		cur.j, cur.k = dq.locate(idx)
+	} else {
+		cur.j = len(dq.chunks)
+	}
+	return cur
+}
+
+// Index returns the index of the value the Cursor points at.
+func (cur *Cursor[T]) Index() int {
+	return cur.idx
+}
+
+// Valid returns whether the Cursor points at a value.
+func (cur *Cursor[T]) Valid() bool {
+	return cur.idx >= 0 && cur.idx < cur.dq.count
+}
+
+// Next moves the Cursor to the next value. It returns false if there
+// is no next value, in which case the Cursor sits after the last value.
+func (cur *Cursor[T]) Next() bool {
+	dq := cur.dq
+	if cur.idx >= dq.count {
+		return false
+	}
+
+	cur.idx++
+	switch {
+	case cur.idx == dq.count:
+		cur.j, cur.k = len(dq.chunks), 0
+		return false
+	case cur.idx == 0:
+		cur.j, cur.k = 0, dq.chunks[0].s
+	default:
+		cur.k++
+		if cur.k == dq.chunks[cur.j].e {
+			cur.j++
+			cur.k = dq.chunks[cur.j].s
+		}
+	}
+	return true
+}
+
+// Prev moves the Cursor to the previous value. It returns false if there
+// is no previous value, in which case the Cursor sits before the first value.
+func (cur *Cursor[T]) Prev() bool {
+	dq := cur.dq
+	if cur.idx < 0 {
+		return false
+	}
+
+	cur.idx--
+	switch {
+	case cur.idx < 0:
+		cur.j, cur.k = -1, 0
+		return false
+	case cur.idx == dq.count-1:
+		cur.j = len(dq.chunks) - 1
+		cur.k = dq.chunks[cur.j].e - 1
+	default:
+		if cur.k == dq.chunks[cur.j].s {
+			cur.j--
+			cur.k = dq.chunks[cur.j].e
+		}
+		cur.k--
+	}
+	return true
+}
+
+func (cur *Cursor[T]) mustBeValid() {
+	if !cur.Valid() {
+		panic(fmt.Errorf("out of range: %d", cur.idx))
+	}
+}
+
+// Value returns the value the Cursor points at. It panics if the Cursor does
+// not point at a value.
+func (cur *Cursor[T]) Value() T {
+	cur.mustBeValid()
+	return cur.dq.chunks[cur.j].data[cur.k]
+}
+
+// Set replaces the value the Cursor points at with v. It panics if the Cursor
+// does not point at a value.
+//
+// If the Deque has a byte limit, Set works like Deque.Replace. If values are
+// evicted from the front, the Cursor keeps pointing at v, or sits before the
+// first value if v is evicted.
+func (cur *Cursor[T]) Set(v T) {
+	cur.mustBeValid()
+	dq := cur.dq
+	if dq.sizeOf != nil {
+		cur.idx = maxInt(cur.idx-dq.replaceAt(cur.j, cur.k, v), -1)
+		cur.sync()
+		return
+	}
+	dq.chunks[cur.j].data[cur.k] = v
+}
+
+func (cur *Cursor[T]) sync() {
+	dq := cur.dq
+	switch {
+	case cur.idx < 0:
+		cur.j, cur.k = -1, 0
+	case cur.idx >= dq.count:
+		cur.j, cur.k = len(dq.chunks), 0
+	default:
+		cur.j, cur.k = dq.seek(cur.idx, cur.j)
+	}
+}
+
+// InsertBefore inserts a new value v before the value the Cursor points at.
+// The Cursor keeps pointing at the same value. If the Cursor sits after the
+// last value, v is added at the back of the Deque. It panics if the Cursor sits
+// before the first value.
+//
+// If the Deque has a max length or a byte limit, InsertBefore follows the overflow
+// policy of the Deque like Insert does. If the value the Cursor points at is
+// evicted, the Cursor sits after the last value, or before the first value if
+// the value is evicted from the front.
+func (cur *Cursor[T]) InsertBefore(v T) {
+	dq := cur.dq
+	if cur.idx < 0 {
+		panic(fmt.Errorf("out of range: %d", cur.idx))
+	}
+	if dq.sizeOf != nil {
+		cur.insertSized(cur.idx, v)
+		return
+	}
+	if dq.count >= dq.maxLen && !cur.makeRoom(v, cur.idx == 0) {
+		return
+	}
+
+	switch {
+	case cur.idx == 0:
+		dq.PushFront(v)
+	case cur.idx == dq.count:
+		dq.PushBack(v)
+	default:
+		dq.insertAt(cur.j, cur.k, v)
+	}
+	cur.idx++
+	cur.sync()
+}
+
+// InsertAfter inserts a new value v after the value the Cursor points at.
+// The Cursor keeps pointing at the same value. If the Cursor sits before the
+// first value, v is added at the front of the Deque. It panics if the Cursor
+// sits after the last value.
+//
+// If the Deque has a max length or a byte limit, InsertAfter follows the overflow
+// policy of the Deque like Insert does. If the value the Cursor points at is
+// evicted, the Cursor sits before the first value, or after the last value if
+// the value is evicted from the back.
+func (cur *Cursor[T]) InsertAfter(v T) {
+	dq := cur.dq
+	if cur.idx >= dq.count {
+		panic(fmt.Errorf("out of range: %d", cur.idx))
+	}
+	if dq.sizeOf != nil {
+		cur.insertSized(cur.idx+1, v)
+		return
+	}
+	if dq.count >= dq.maxLen && !cur.makeRoom(v, cur.idx < 0) {
+		return
+	}
+
+	switch {
+	case cur.idx < 0:
+		dq.PushFront(v)
+	case cur.idx == dq.count-1:
+		dq.PushBack(v)
+	default:
+		j, k := cur.j, cur.k+1
+		if k == dq.chunks[j].e {
+			j++
+			k = dq.chunks[j].s
+		}
+		dq.insertAt(j, k, v)
+	}
+	cur.sync()
+}
+
+// makeRoom is similar to Deque.makeRoom except that it keeps the Cursor pointing
+// at the same value, unless the value is evicted.
+func (cur *Cursor[T]) makeRoom(v T, front bool) bool {
+	if !cur.dq.makeRoom(v, front) {
+		return false
+	}
+	if !front {
+		cur.idx--
+	}
+	cur.sync()
+	return true
+}
+
+// insertSized inserts v at idx of a Deque with a byte limit, and keeps the Cursor
+// pointing at the same value, unless the value is evicted.
+func (cur *Cursor[T]) insertSized(idx int, v T) {
+	dq := cur.dq
+	n := dq.count
+	var ok bool
+	switch {
+	case idx <= 0:
+		ok = dq.TryPushFront(v)
+	case idx >= n:
+		ok = dq.TryPushBack(v)
+	default:
+		ok = dq.insertSized(idx, v)
+	}
+	if !ok {
+		return
+	}
+
+	if idx <= cur.idx {
+		cur.idx++
+	}
+	if idx > 0 {
+		// The values are evicted from the front.
+		cur.idx -= n + 1 - dq.count
+	}
+	cur.idx = minInt(maxInt(cur.idx, -1), dq.count)
+	cur.sync()
+}
+
+// Remove removes the value the Cursor points at and moves the Cursor to the
+// next value, or after the last value if there is none. It panics if the
+// Cursor does not point at a value.
+func (cur *Cursor[T]) Remove() {
+	cur.mustBeValid()
+	cur.dq.removeAt(cur.j, cur.k)
+	cur.sync()
+}
@@ -0,0 +1,144 @@
+package deque
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+//gocyclo:ignore
+func TestCursor_Navigation(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	cur := dq.CursorAt(0)
+	if cur.Valid() || cur.Next() || cur.Index() != 0 {
+		t.Fatal(`cur.Valid() || cur.Next() || cur.Index() != 0`)
+	}
+	if cur.Prev() || cur.Index() != -1 || cur.Prev() {
+		t.Fatal(`cur.Prev() || cur.Index() != -1 || cur.Prev()`)
+	}
+
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+	}
+	for i := 0; i < 20; i++ {
+		dq.Remove(rand.Intn(dq.Len()))
+	}
+	a := dq.Dump()
+
+	cur = dq.CursorAt(0)
+	for i := 0; i < len(a); i++ {
+		if !cur.Valid() || cur.Index() != i || cur.Value() != a[i] {
+			t.Fatalf("unexpected cursor state. i: %d", i)
+		}
+		if cur.Next() != (i+1 < len(a)) {
+			t.Fatalf("unexpected result of Next. i: %d", i)
+		}
+	}
+	if cur.Valid() || cur.Index() != len(a) {
+		t.Fatal(`cur.Valid() || cur.Index() != len(a)`)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		if !cur.Prev() || cur.Value() != a[i] {
+			t.Fatalf("unexpected result of Prev. i: %d", i)
+		}
+	}
+	if cur.Prev() || cur.Index() != -1 {
+		t.Fatal(`cur.Prev() || cur.Index() != -1`)
+	}
+	if !cur.Next() || cur.Value() != a[0] {
+		t.Fatal(`!cur.Next() || cur.Value() != a[0]`)
+	}
+
+	cur = dq.CursorAt(len(a) / 2)
+	cur.Set(-1)
+	if dq.Peek(len(a)/2) != -1 {
+		t.Fatal(`dq.Peek(len(a)/2) != -1`)
+	}
+
+	for _, idx := range []int{-1, dq.Len() + 1} {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			dq.CursorAt(idx)
+			t.Fatal("CursorAt should panic")
+		}()
+	}
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		dq.CursorAt(dq.Len()).Value()
+		t.Fatal("Value should panic")
+	}()
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		dq.CursorAt(dq.Len()).InsertAfter(1)
+		t.Fatal("InsertAfter should panic")
+	}()
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		cur := dq.CursorAt(0)
+		cur.Prev()
+		cur.InsertBefore(1)
+		t.Fatal("InsertBefore should panic")
+	}()
+}
+
+//gocyclo:ignore
+func TestCursor_Random(t *testing.T) {
+	seed := time.Now().UnixMilli()
+	t.Logf("seed: %d", seed)
+	r := rand.New(rand.NewSource(seed))
+	dq := NewDeque[int](WithChunkSize(8))
+	var a []int
+	for i := 0; i < 200; i++ {
+		dq.PushBack(i)
+		a = append(a, i)
+	}
+
+	cur := dq.CursorAt(r.Intn(len(a)))
+	for i := 1000; i < 50000; i++ {
+		switch r.Intn(6) {
+		case 0:
+			cur.Next()
+		case 1:
+			cur.Prev()
+		case 2:
+			if cur.Index() >= 0 {
+				idx := cur.Index()
+				cur.InsertBefore(i)
+				a = append(a[:idx], append([]int{i}, a[idx:]...)...)
+			}
+		case 3:
+			if cur.Index() < len(a) {
+				idx := cur.Index() + 1
+				cur.InsertAfter(i)
+				a = append(a[:idx], append([]int{i}, a[idx:]...)...)
+			}
+		case 4, 5:
+			if cur.Valid() {
+				idx := cur.Index()
+				cur.Remove()
+				a = append(a[:idx], a[idx+1:]...)
+			}
+		}
+
+		invariant(t, dq, skipChunkMerge())
+		if dq.Len() != len(a) {
+			t.Fatalf("dq.Len() != len(a). i: %d", i)
+		}
+		if idx := cur.Index(); idx >= 0 && idx < len(a) {
+			if cur.Value() != a[idx] {
+				t.Fatalf("cur.Value() != a[idx]. i: %d", i)
+			}
+		}
+		if i%1000 == 0 {
+			checkValues(t, dq, a...)
+		}
+	}
+}
@@ -6,21 +6,25 @@ package deque
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"unsafe"
 )
 
 const (
 	defaultPitchSize = 64
+	maxLenUnlimited  = math.MaxInt
 )
 
 var (
 	errEmpty = errors.New("deque is empty")
+	errFull  = errors.New("deque is full")
 )
 
 type chunk[T any] struct {
 	s    int
 	e    int // not included
+	base int // the absolute position of data[0]
 	data []T
 }
 
@@ -48,6 +52,33 @@ type Deque[T any] struct {
 	eFree      int
 	chunkSize  int
 	chunkPool  sync.Pool
+
+	// The bases of the chunks in chunkPitch[gapLo:gapHi] may be out of date.
+	// See locate.
+	gapLo int
+	gapHi int
+
+	reserved  []*chunk[T]
+	nReserved int
+
+	maxLen  int
+	policy  OverflowPolicy
+	onEvict func(T)
+
+	maxBytes int64
+	bytes    int64
+	sizeOf   func(T) int64
+
+	high   int
+	low    int
+	onHigh func()
+	onLow  func()
+	above  bool
+
+	// PushBack and PushFront take the slow path if count >= limit, and the pop
+	// methods take the slow path if count <= floor after a value is removed.
+	limit int
+	floor int
 }
 
 func minInt(a, b int) int {
@@ -68,6 +99,16 @@ func maxInt(a, b int) int {
 
 type optionHolder struct {
 	chunkSize int
+	maxLen    int
+	policy    OverflowPolicy
+	onEvict   any
+	growth    bool
+	maxBytes  int64
+	sizeOf    any
+	high      int
+	low       int
+	onHigh    func()
+	onLow     func()
 }
 
 // NewDeque creates a new Deque instance.
@@ -85,6 +126,38 @@ func NewDeque[T any](opts ...Option) *Deque[T] {
 	}
 
 	dq.chunkSize = holder.chunkSize
+	dq.maxLen = maxLenUnlimited
+	if holder.maxLen > 0 {
+		dq.maxLen = holder.maxLen
+	}
+	dq.policy = holder.policy
+	if holder.onEvict != nil {
+		f, ok := holder.onEvict.(func(T))
+		if !ok {
+			panic(fmt.Errorf("the callback of WithOnEvict should be %T, not %T", f, holder.onEvict))
+		}
+		dq.onEvict = f
+	}
+	dq.maxBytes = math.MaxInt64
+	if holder.sizeOf != nil {
+		f, ok := holder.sizeOf.(func(T) int64)
+		if !ok {
+			panic(fmt.Errorf("the size function of WithMaxBytes should be %T, not %T", f, holder.sizeOf))
+		}
+		dq.sizeOf = f
+		if holder.maxBytes > 0 {
+			dq.maxBytes = holder.maxBytes
+		}
+	}
+	dq.high = maxLenUnlimited
+	if holder.high > 0 {
+		if holder.low < 0 || holder.low >= holder.high {
+			panic(fmt.Errorf("invalid watermarks: high %d, low %d", holder.high, holder.low))
+		}
+		dq.high, dq.low = holder.high, holder.low
+		dq.onHigh, dq.onLow = holder.onHigh, holder.onLow
+	}
+	dq.updateLimits()
 	dq.chunkPool = sync.Pool{
 		New: func() any {
 			return &chunk[T]{
@@ -96,10 +169,32 @@ func NewDeque[T any](opts ...Option) *Deque[T] {
 	return dq
 }
 
+// NewDequeFromSlice creates a new Deque instance holding all the values in vs.
+func NewDequeFromSlice[T any](vs []T, opts ...Option) *Deque[T] {
+	dq := NewDeque[T](opts...)
+	dq.PushBackSlice(vs)
+	return dq
+}
+
+// newSibling creates an empty Deque with the same options as dq, except for
+// the watermarks.
+func (dq *Deque[T]) newSibling() *Deque[T] {
+	nd := NewDeque[T](WithChunkSize(dq.chunkSize))
+	nd.maxLen = dq.maxLen
+	nd.policy = dq.policy
+	nd.onEvict = dq.onEvict
+	nd.maxBytes = dq.maxBytes
+	nd.sizeOf = dq.sizeOf
+	nd.updateLimits()
+	return nd
+}
+
 func (dq *Deque[T]) balance() {
 	var pitchLen = len(dq.chunkPitch)
 	n := len(dq.chunks)
+	old := dq.sFree
 	dq.sFree = pitchLen/2 - n/2
+	dq.shiftGap(math.MinInt, math.MaxInt, dq.sFree-old)
 	dq.eFree = pitchLen - dq.sFree - n
 	newChunks := dq.chunkPitch[dq.sFree : dq.sFree+n]
 	copy(newChunks, dq.chunks)
@@ -117,24 +212,50 @@ func (dq *Deque[T]) realloc() {
 		dq.balance()
 		return
 	}
+	dq.grow()
+}
 
+func (dq *Deque[T]) grow() {
 	newLen := len(dq.chunkPitch) * 2
 	newPitch := make([]*chunk[T], newLen, newLen)
 	n := len(dq.chunks)
+	old := dq.sFree
 	dq.sFree = (newLen - n) / 2
 	dq.eFree = (newLen - n) - dq.sFree
+	dq.shiftGap(math.MinInt, math.MaxInt, dq.sFree-old)
 	chunks := newPitch[dq.sFree : dq.sFree+n]
 	copy(chunks, dq.chunks)
 	dq.chunkPitch = newPitch
 	dq.chunks = chunks
 }
 
+// reserve makes sure that there are at least n free slots at both ends of dq.chunkPitch.
+func (dq *Deque[T]) reserve(n int) {
+	for dq.sFree < n || dq.eFree < n {
+		if len(dq.chunks)+n*2 < len(dq.chunkPitch)/2 {
+			dq.balance()
+		} else {
+			dq.grow()
+		}
+	}
+}
+
 func (dq *Deque[T]) expandEnd() {
+	c := dq.chunkPool.Get().(*chunk[T])
+	c.s, c.e = 0, 0
+	dq.appendChunk(c)
+}
+
+func (dq *Deque[T]) appendChunk(c *chunk[T]) {
 	if f := dq.eFree; f == 0 {
 		dq.realloc()
 	}
-	c := dq.chunkPool.Get().(*chunk[T])
-	c.s, c.e = 0, 0
+	if n := len(dq.chunks); n > 0 {
+		last := dq.chunks[n-1]
+		c.base = last.base + last.e - c.s
+	} else {
+		c.base = 0
+	}
 	dq.eFree--
 	newEnd := len(dq.chunkPitch) - dq.eFree
 	dq.chunkPitch[newEnd-1] = c
@@ -142,11 +263,21 @@ func (dq *Deque[T]) expandEnd() {
 }
 
 func (dq *Deque[T]) expandStart() {
+	c := dq.chunkPool.Get().(*chunk[T])
+	c.s, c.e = dq.chunkSize, dq.chunkSize
+	dq.prependChunk(c)
+}
+
+func (dq *Deque[T]) prependChunk(c *chunk[T]) {
 	if f := dq.sFree; f == 0 {
 		dq.realloc()
 	}
-	c := dq.chunkPool.Get().(*chunk[T])
-	c.s, c.e = dq.chunkSize, dq.chunkSize
+	if len(dq.chunks) > 0 {
+		first := dq.chunks[0]
+		c.base = first.base + first.s - c.e
+	} else {
+		c.base = 0
+	}
 	dq.sFree--
 	dq.chunkPitch[dq.sFree] = c
 	pitchLen := len(dq.chunkPitch)
@@ -154,38 +285,60 @@ func (dq *Deque[T]) expandStart() {
 }
 
 func (dq *Deque[T]) shrinkEnd() {
+	dq.chunkPool.Put(dq.detachEnd())
+}
+
+// detachEnd removes the last chunk from dq.chunks and returns it.
+func (dq *Deque[T]) detachEnd() *chunk[T] {
 	pitchLen := len(dq.chunkPitch)
 	dq.eFree++
 	newEnd := pitchLen - dq.eFree
 	c := dq.chunkPitch[newEnd]
 	dq.chunkPitch[newEnd] = nil
-	dq.chunkPool.Put(c)
 	dq.chunks = dq.chunkPitch[dq.sFree:newEnd]
 
-	if dq.sFree+dq.eFree < pitchLen {
-		return
+	if dq.sFree+dq.eFree >= pitchLen {
+		dq.sFree = pitchLen / 2
+		dq.eFree = pitchLen - dq.sFree
+		dq.gapLo, dq.gapHi = 0, 0
 	}
-	dq.sFree = pitchLen / 2
-	dq.eFree = pitchLen - dq.sFree
+	return c
 }
 
 func (dq *Deque[T]) shrinkStart() {
+	dq.chunkPool.Put(dq.detachStart())
+}
+
+// detachStart removes the first chunk from dq.chunks and returns it.
+func (dq *Deque[T]) detachStart() *chunk[T] {
 	c := dq.chunkPitch[dq.sFree]
 	dq.chunkPitch[dq.sFree] = nil
-	dq.chunkPool.Put(c)
 	dq.sFree++
 	pitchLen := len(dq.chunkPitch)
 	dq.chunks = dq.chunkPitch[dq.sFree : pitchLen-dq.eFree]
 
-	if dq.sFree+dq.eFree < pitchLen {
-		return
+	if dq.sFree+dq.eFree >= pitchLen {
+		dq.sFree = pitchLen / 2
+		dq.eFree = pitchLen - dq.sFree
+		dq.gapLo, dq.gapHi = 0, 0
 	}
-	dq.sFree = pitchLen / 2
-	dq.eFree = pitchLen - dq.sFree
+	return c
 }
 
 // PushBack adds a new value at the back of dq.
+//
+// If dq has a max length and is full, or v does not fit in the byte budget of dq,
+// PushBack follows the overflow policy of dq.
 func (dq *Deque[T]) PushBack(v T) {
+	if dq.count >= dq.limit {
+		dq.TryPushBack(v)
+		return
+	}
+	dq.pushBack(v)
+}
+
+// pushBack is the same as PushBack except that it ignores the limits of dq.
+func (dq *Deque[T]) pushBack(v T) {
 	n := len(dq.chunks)
 	if n == 0 || dq.chunks[n-1].e == dq.chunkSize {
 		dq.expandEnd()
@@ -198,7 +351,19 @@ func (dq *Deque[T]) PushBack(v T) {
 }
 
 // PushFront adds a new value at the front of dq.
+//
+// If dq has a max length and is full, or v does not fit in the byte budget of dq,
+// PushFront follows the overflow policy of dq.
 func (dq *Deque[T]) PushFront(v T) {
+	if dq.count >= dq.limit {
+		dq.TryPushFront(v)
+		return
+	}
+	dq.pushFront(v)
+}
+
+// pushFront is the same as PushFront except that it ignores the limits of dq.
+func (dq *Deque[T]) pushFront(v T) {
 	n := len(dq.chunks)
 	if n == 0 || dq.chunks[0].s == 0 {
 		dq.expandStart()
@@ -209,6 +374,142 @@ func (dq *Deque[T]) PushFront(v T) {
 	dq.count++
 }
 
+// PushBackSlice adds all the values in vs at the back of dq, keeping their order.
+//
+// If dq has a max length or a byte limit, PushBackSlice follows the overflow policy
+// of dq.
+func (dq *Deque[T]) PushBackSlice(vs []T) {
+	dq.pushBackSlice(dq.admit(vs, false))
+	dq.watch()
+}
+
+func (dq *Deque[T]) pushBackSlice(vs []T) {
+	for len(vs) > 0 {
+		n := len(dq.chunks)
+		if n == 0 || dq.chunks[n-1].e == dq.chunkSize {
+			dq.expandEnd()
+			n++
+		}
+		c := dq.chunks[n-1]
+		num := copy(c.data[c.e:], vs)
+		c.e += num
+		dq.count += num
+		vs = vs[num:]
+	}
+}
+
+// PushFrontSlice adds all the values in vs at the front of dq, keeping their order.
+// After the call, vs[0] is the first value of dq.
+//
+// If dq has a max length or a byte limit, PushFrontSlice follows the overflow policy
+// of dq.
+func (dq *Deque[T]) PushFrontSlice(vs []T) {
+	dq.pushFrontSlice(dq.admit(vs, true))
+	dq.watch()
+}
+
+func (dq *Deque[T]) pushFrontSlice(vs []T) {
+	for len(vs) > 0 {
+		if len(dq.chunks) == 0 || dq.chunks[0].s == 0 {
+			dq.expandStart()
+		}
+		c := dq.chunks[0]
+		num := minInt(c.s, len(vs))
+		c.s -= num
+		copy(c.data[c.s:], vs[len(vs)-num:])
+		dq.count += num
+		vs = vs[:len(vs)-num]
+	}
+}
+
+// ReserveBack reserves space for n values at the back of dq and returns it as
+// a list of writable slices, so that the values can be written in place, e.g.
+// by a decoder. The reserved space becomes part of dq only after CommitBack is
+// called. A new call to ReserveBack or Clear cancels the previous reservation.
+//
+// Do NOT modify dq in any other way between ReserveBack and CommitBack.
+func (dq *Deque[T]) ReserveBack(n int) [][]T {
+	dq.cancelReservation()
+	if n <= 0 {
+		return nil
+	}
+
+	dq.nReserved = n
+	var segs [][]T
+	if len(dq.chunks) > 0 {
+		if last := dq.chunks[len(dq.chunks)-1]; last.e < dq.chunkSize {
+			num := minInt(n, dq.chunkSize-last.e)
+			segs = append(segs, last.data[last.e:last.e+num:last.e+num])
+			n -= num
+		}
+	}
+	for n > 0 {
+		c := dq.chunkPool.Get().(*chunk[T])
+		c.s, c.e = 0, 0
+		dq.reserved = append(dq.reserved, c)
+		num := minInt(n, dq.chunkSize)
+		segs = append(segs, c.data[:num:num])
+		n -= num
+	}
+	return segs
+}
+
+// CommitBack adds the first k values of the space reserved by ReserveBack at the
+// back of dq, and releases the rest of the space. It panics if k is negative or
+// greater than the size of the reserved space.
+//
+// If dq has a max length or a byte limit, CommitBack follows the overflow policy of
+// dq. The values that do not fit are dropped from the back.
+func (dq *Deque[T]) CommitBack(k int) {
+	if k < 0 || k > dq.nReserved {
+		panic(fmt.Errorf("out of range: %d", k))
+	}
+	if dq.policy == Panic && dq.count+k > dq.maxLen {
+		dq.cancelReservation()
+		panic(errFull)
+	}
+
+	var defVal T
+	n := k
+	dq.count += k
+	if len(dq.chunks) > 0 {
+		last := dq.chunks[len(dq.chunks)-1]
+		num := minInt(k, dq.chunkSize-last.e)
+		last.e += num
+		for i := last.e; i < dq.chunkSize; i++ {
+			last.data[i] = defVal
+		}
+		k -= num
+	}
+	for i, c := range dq.reserved {
+		c.e = minInt(k, dq.chunkSize)
+		for j := c.e; j < dq.chunkSize; j++ {
+			c.data[j] = defVal
+		}
+		if c.e > 0 {
+			dq.appendChunk(c)
+		} else {
+			dq.chunkPool.Put(c)
+		}
+		k -= c.e
+		dq.reserved[i] = nil
+	}
+	dq.reserved = dq.reserved[:0]
+	dq.nReserved = 0
+
+	if dq.sizeOf != nil {
+		dq.bytes += dq.sizeOfRange(dq.count-n, dq.count)
+	}
+	dq.shedBack(n)
+	dq.watch()
+}
+
+func (dq *Deque[T]) cancelReservation() {
+	if dq.nReserved > 0 {
+		dq.CommitBack(0)
+	}
+}
+
 // TryPopBack tries to remove a value from the back of dq and returns the removed value if any.
 // The return value ok indicates whether it succeeded.
 func (dq *Deque[T]) TryPopBack() (_ T, ok bool) {
@@ -234,6 +535,9 @@ func (dq *Deque[T]) TryPopBack() (_ T, ok bool) {
 		}
 	}
 	dq.count--
+	if dq.count <= dq.floor {
+		dq.popped(r)
+	}
 	return r, true
 }
 
@@ -262,6 +566,9 @@ func (dq *Deque[T]) PopBack() T {
 		}
 	}
 	dq.count--
+	if dq.count <= dq.floor {
+		dq.popped(r)
+	}
 	return r
 }
 
@@ -290,6 +597,9 @@ func (dq *Deque[T]) TryPopFront() (_ T, ok bool) {
 		}
 	}
 	dq.count--
+	if dq.count <= dq.floor {
+		dq.popped(r)
+	}
 	return r, true
 }
 
@@ -318,9 +628,96 @@ func (dq *Deque[T]) PopFront() T {
 		}
 	}
 	dq.count--
+	if dq.count <= dq.floor {
+		dq.popped(r)
+	}
 	return r
 }
 
+// TryPopFrontIf is similar to TryPopFront except that it removes the first value
+// of dq only if pred returns true for it.
+func (dq *Deque[T]) TryPopFrontIf(pred func(T) bool) (_ T, ok bool) {
+	if dq.count == 0 {
+		return *new(T), false
+	}
+	if c := dq.chunks[0]; !pred(c.data[c.s]) {
+		return *new(T), false
+	}
+	return dq.TryPopFront()
+}
+
+// TryPopBackIf is similar to TryPopBack except that it removes the last value
+// of dq only if pred returns true for it.
+func (dq *Deque[T]) TryPopBackIf(pred func(T) bool) (_ T, ok bool) {
+	if dq.count == 0 {
+		return *new(T), false
+	}
+	if c := dq.chunks[len(dq.chunks)-1]; !pred(c.data[c.e-1]) {
+		return *new(T), false
+	}
+	return dq.TryPopBack()
+}
+
+// PopFrontWhile removes values from the front of dq as long as pred returns true
+// for them, and returns the number of the removed values.
+func (dq *Deque[T]) PopFrontWhile(pred func(T) bool) int {
+	var n int
+	for {
+		if _, ok := dq.TryPopFrontIf(pred); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// PopFrontWhileWithBuffer is similar to PopFrontWhile except that it returns
+// the removed values, or nil if no value is removed. It uses buf to store the
+// removed values as long as it has enough space.
+func (dq *Deque[T]) PopFrontWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	buf = buf[:0]
+	for {
+		v, ok := dq.TryPopFrontIf(pred)
+		if !ok {
+			break
+		}
+		buf = append(buf, v)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
+
+// PopBackWhile removes values from the back of dq as long as pred returns true
+// for them, and returns the number of the removed values.
+func (dq *Deque[T]) PopBackWhile(pred func(T) bool) int {
+	var n int
+	for {
+		if _, ok := dq.TryPopBackIf(pred); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// PopBackWhileWithBuffer is similar to PopBackWhile except that it returns
+// the removed values in the order they are removed, or nil if no value is
+// removed. It uses buf to store the removed values as long as it has enough space.
+func (dq *Deque[T]) PopBackWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	buf = buf[:0]
+	for {
+		v, ok := dq.TryPopBackIf(pred)
+		if !ok {
+			break
+		}
+		buf = append(buf, v)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
+
 // DequeueMany removes a number of values from the front of dq and returns
 // the removed values or nil if dq is empty.
 //
@@ -363,9 +760,241 @@ func (dq *Deque[T]) DequeueManyWithBuffer(max int, buf []T) []T {
 	}
 
 	dq.count -= len(buf)
+	dq.bytes -= dq.sizeOfSlice(buf)
+	dq.watch()
+	return buf
+}
+
+// PopBackMany removes a number of values from the back of dq and returns
+// the removed values or nil if dq is empty. The values are returned in
+// the order PopBack would remove them, i.e. the last value of dq comes first.
+//
+// If max <= 0, PopBackMany removes and returns all the values in dq.
+func (dq *Deque[T]) PopBackMany(max int) []T {
+	return dq.popBackMany(max, nil, true)
+}
+
+// PopBackManyWithBuffer is similar to PopBackMany except that it uses
+// buf to store the removed values as long as it has enough space.
+func (dq *Deque[T]) PopBackManyWithBuffer(max int, buf []T) []T {
+	return dq.popBackMany(max, buf, true)
+}
+
+// DequeueManyFromBack is similar to PopBackMany except that the removed
+// values keep their original order, i.e. the last value of dq comes last.
+func (dq *Deque[T]) DequeueManyFromBack(max int) []T {
+	return dq.popBackMany(max, nil, false)
+}
+
+// DequeueManyFromBackWithBuffer is similar to DequeueManyFromBack except that
+// it uses buf to store the removed values as long as it has enough space.
+func (dq *Deque[T]) DequeueManyFromBackWithBuffer(max int, buf []T) []T {
+	return dq.popBackMany(max, buf, false)
+}
+
+func (dq *Deque[T]) popBackMany(max int, buf []T, tailFirst bool) []T {
+	n := dq.count
+	if n == 0 {
+		return nil
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	if n <= cap(buf) {
+		buf = buf[:n]
+	} else {
+		buf = make([]T, n)
+	}
+
+	var defVal T
+	for n > 0 {
+		c := dq.chunks[len(dq.chunks)-1]
+		num := minInt(n, c.e-c.s)
+		src := c.data[c.e-num : c.e]
+		if tailFirst {
+			dst := buf[len(buf)-n:]
+			for j := 0; j < num; j++ {
+				dst[j] = src[num-1-j]
+			}
+		} else {
+			copy(buf[n-num:], src)
+		}
+		for j := range src {
+			src[j] = defVal
+		}
+		c.e -= num
+		if c.e == c.s {
+			dq.shrinkEnd()
+		}
+		n -= num
+	}
+
+	dq.count -= len(buf)
+	dq.bytes -= dq.sizeOfSlice(buf)
+	dq.watch()
 	return buf
 }
 
+// DiscardFront removes at most n values from the front of dq and returns
+// the number of the removed values.
+func (dq *Deque[T]) DiscardFront(n int) int {
+	n = minInt(n, dq.count)
+	if n <= 0 {
+		return 0
+	}
+
+	var defVal T
+	for remaining := n; remaining > 0; {
+		c := dq.chunks[0]
+		num := minInt(remaining, c.e-c.s)
+		dq.bytes -= dq.sizeOfSlice(c.data[c.s : c.s+num])
+		for j := c.s; j < c.s+num; j++ {
+			c.data[j] = defVal
+		}
+		c.s += num
+		if c.s == c.e {
+			dq.shrinkStart()
+		}
+		remaining -= num
+	}
+
+	dq.count -= n
+	dq.watch()
+	return n
+}
+
+// DiscardBack removes at most n values from the back of dq and returns
+// the number of the removed values.
+func (dq *Deque[T]) DiscardBack(n int) int {
+	n = minInt(n, dq.count)
+	if n <= 0 {
+		return 0
+	}
+
+	var defVal T
+	for remaining := n; remaining > 0; {
+		c := dq.chunks[len(dq.chunks)-1]
+		num := minInt(remaining, c.e-c.s)
+		dq.bytes -= dq.sizeOfSlice(c.data[c.e-num : c.e])
+		for j := c.e - num; j < c.e; j++ {
+			c.data[j] = defVal
+		}
+		c.e -= num
+		if c.e == c.s {
+			dq.shrinkEnd()
+		}
+		remaining -= num
+	}
+
+	dq.count -= n
+	dq.watch()
+	return n
+}
+
+// Rotate moves n values from the front of dq to the back if n > 0, or -n values
+// from the back of dq to the front if n < 0. Rotate(1) is equivalent to
+// dq.PushBack(dq.PopFront()) but much faster when n is large, because Rotate moves
+// whole chunks between the two ends and only copies the values in the partial
+// chunks.
+func (dq *Deque[T]) Rotate(n int) {
+	if dq.count <= 1 {
+		return
+	}
+	n %= dq.count
+	if n < 0 {
+		n += dq.count
+	}
+	if n <= dq.count/2 {
+		dq.rotateToBack(n)
+	} else {
+		dq.rotateToFront(dq.count - n)
+	}
+}
+
+func (dq *Deque[T]) rotateToBack(n int) {
+	var defVal T
+	for n > 0 {
+		c0 := dq.chunks[0]
+		last := dq.chunks[len(dq.chunks)-1]
+		num := c0.e - c0.s
+		if len(dq.chunks) > 1 && last.e == dq.chunkSize && num <= n {
+			if dq.eFree == 0 {
+				dq.realloc()
+			}
+			c0.base = last.base + last.e - c0.s
+			dq.chunkPitch[dq.sFree] = nil
+			dq.sFree++
+			dq.eFree--
+			newEnd := len(dq.chunkPitch) - dq.eFree
+			dq.chunkPitch[newEnd-1] = c0
+			dq.chunks = dq.chunkPitch[dq.sFree:newEnd]
+			n -= num
+			continue
+		}
+
+		if last.e == dq.chunkSize {
+			dq.expandEnd()
+			last = dq.chunks[len(dq.chunks)-1]
+		}
+		num = minInt(minInt(n, num), dq.chunkSize-last.e)
+		copy(last.data[last.e:], c0.data[c0.s:c0.s+num])
+		for i := c0.s; i < c0.s+num; i++ {
+			c0.data[i] = defVal
+		}
+		c0.s += num
+		last.e += num
+		if c0.s == c0.e {
+			dq.shrinkStart()
+		}
+		n -= num
+	}
+
+	dq.mergeChunks(len(dq.chunks) - 2)
+	dq.mergeChunks(0)
+}
+
+func (dq *Deque[T]) rotateToFront(n int) {
+	var defVal T
+	for n > 0 {
+		c0 := dq.chunks[0]
+		last := dq.chunks[len(dq.chunks)-1]
+		num := last.e - last.s
+		if len(dq.chunks) > 1 && c0.s == 0 && num <= n {
+			if dq.sFree == 0 {
+				dq.realloc()
+			}
+			last.base = c0.base + c0.s - last.e
+			pitchLen := len(dq.chunkPitch)
+			dq.chunkPitch[pitchLen-dq.eFree-1] = nil
+			dq.eFree++
+			dq.sFree--
+			dq.chunkPitch[dq.sFree] = last
+			dq.chunks = dq.chunkPitch[dq.sFree : pitchLen-dq.eFree]
+			n -= num
+			continue
+		}
+
+		if c0.s == 0 {
+			dq.expandStart()
+			c0 = dq.chunks[0]
+		}
+		num = minInt(minInt(n, num), c0.s)
+		c0.s -= num
+		copy(c0.data[c0.s:], last.data[last.e-num:last.e])
+		for i := last.e - num; i < last.e; i++ {
+			last.data[i] = defVal
+		}
+		last.e -= num
+		if last.s == last.e {
+			dq.shrinkEnd()
+		}
+		n -= num
+	}
+
+	dq.mergeChunks(0)
+	dq.mergeChunks(len(dq.chunks) - 2)
+}
+
 // Back returns the last value of dq if any. The return value ok
 // indicates whether it succeeded.
 func (dq *Deque[T]) Back() (_ T, ok bool) {
@@ -442,41 +1071,272 @@ func (dq *Deque[T]) Range(f func(i int, v T) bool) {
 	}
 }
 
+// locate returns the index of the chunk holding the value at idx and the
+// position of the value inside the chunk. idx must be in range.
+//
+// Every chunk records the absolute position of its data[0], and neighboring
+// chunks are contiguous: c.base+c.e of a chunk equals next.base+next.s of the
+// following one. So the chunk holding idx is found by a binary search, and
+// when the chunks between the two ends are full, which is the common case,
+// the first guess is already correct.
+//
+// Insert and Remove shift the values on one side of the modified chunks, which
+// breaks the contiguity there. Instead of walking the chunks on that side, they
+// only mark the modified chunks as a gap with reindex. The chunks before the gap
+// are contiguous with the first chunk, those after it with the last chunk, and
+// the bases inside the gap are recomputed when locate reaches them.
+func (dq *Deque[T]) locate(idx int) (j, k int) {
+	v, w := dq.gap()
+	first := dq.chunks[0]
+	a := first.base + first.s + idx
+	if j = (first.s + idx) / dq.chunkSize; j < v {
+		c := dq.chunks[j]
+		if a >= c.base+c.s && a < c.base+c.e {
+			return j, a - c.base
+		}
+	}
+	if c := dq.chunks[v-1]; a < c.base+c.e {
+		return dq.bisect(0, v-1, a)
+	}
+
+	n := len(dq.chunks)
+	last := dq.chunks[n-1]
+	b := last.base + last.e - (dq.count - idx)
+	if c := dq.chunks[w]; b >= c.base+c.s {
+		return dq.bisect(w, n-1, b)
+	}
+	return dq.fill(idx, v, w)
+}
+
+// bisect returns the chunk in [lo, hi] holding the absolute position a, and the
+// position of a inside the chunk.
+func (dq *Deque[T]) bisect(lo, hi, a int) (j, k int) {
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		c := dq.chunks[mid]
+		if c.base+c.e <= a {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, a - dq.chunks[lo].base
+}
+
+// seek is similar to locate except that it tries the chunks around j first.
+func (dq *Deque[T]) seek(idx, j int) (_, k int) {
+	v, w := dq.gap()
+	first, last := dq.chunks[0], dq.chunks[len(dq.chunks)-1]
+	a := first.base + first.s + idx
+	b := last.base + last.e - (dq.count - idx)
+	for h := maxInt(j-1, 0); h <= minInt(j+1, len(dq.chunks)-1); h++ {
+		c := dq.chunks[h]
+		if h < v && a >= c.base+c.s && a < c.base+c.e {
+			return h, a - c.base
+		}
+		if h >= w && b >= c.base+c.s && b < c.base+c.e {
+			return h, b - c.base
+		}
+	}
+	return dq.locate(idx)
+}
+
+// gap returns the range [v, w) of the chunks whose bases may be out of date.
+// The first chunk and the last chunk are never in the range. v == w == len(dq.chunks)
+// means all the chunks are contiguous.
+func (dq *Deque[T]) gap() (v, w int) {
+	n := len(dq.chunks)
+	v, w = dq.gapLo-dq.sFree, dq.gapHi-dq.sFree
+	if n <= 1 || w <= 0 || v >= n {
+		return n, n
+	}
+	return maxInt(v, 1), minInt(w, n-1)
+}
+
+// fill recomputes the bases of the chunks in the gap [v, w) from the nearer side
+// of idx until it reaches the chunk holding idx, and returns the chunk and the
+// position of idx inside it like locate.
+func (dq *Deque[T]) fill(idx, v, w int) (j, k int) {
+	first, last := dq.chunks[0], dq.chunks[len(dq.chunks)-1]
+	p := dq.chunks[v-1]
+	s := dq.chunks[w]
+	head := p.base + p.e - (first.base + first.s)
+	tail := dq.count - (last.base + last.e - (s.base + s.s))
+	if idx-head <= tail-idx {
+		a := first.base + first.s + idx
+		for j = v; ; j++ {
+			c, prev := dq.chunks[j], dq.chunks[j-1]
+			c.base = prev.base + prev.e - c.s
+			if a < c.base+c.e {
+				dq.gapLo = dq.sFree + j + 1
+				return j, a - c.base
+			}
+		}
+	}
+
+	b := last.base + last.e - (dq.count - idx)
+	for j = w - 1; ; j-- {
+		c, next := dq.chunks[j], dq.chunks[j+1]
+		c.base = next.base + next.s - c.e
+		if b >= c.base+c.s {
+			dq.gapHi = dq.sFree + j
+			return j, b - c.base
+		}
+	}
+}
+
+// index returns the index of the value at position k of dq.chunks[j].
+func (dq *Deque[T]) index(j, k int) int {
+	v, w := dq.gap()
+	if j >= w {
+		last := dq.chunks[len(dq.chunks)-1]
+		return dq.count - (last.base + last.e - (dq.chunks[j].base + k))
+	}
+	for ; v <= j; v++ {
+		c, prev := dq.chunks[v], dq.chunks[v-1]
+		c.base = prev.base + prev.e - c.s
+		dq.gapLo = dq.sFree + v + 1
+	}
+	first := dq.chunks[0]
+	return dq.chunks[j].base + k - (first.base + first.s)
+}
+
+// reindex adds the chunks in [lo, hi], which have been modified, to the gap of
+// the chunks whose bases may be out of date. See locate.
+func (dq *Deque[T]) reindex(lo, hi int) {
+	n := len(dq.chunks)
+	lo = maxInt(lo, 0)
+	hi = minInt(hi, n-1)
+	if lo > hi {
+		return
+	}
+
+	lo, hi = dq.sFree+lo, dq.sFree+hi+1
+	if v, _ := dq.gap(); v < n {
+		lo = minInt(lo, dq.gapLo)
+		hi = maxInt(hi, dq.gapHi)
+	}
+	dq.gapLo, dq.gapHi = lo, hi
+}
+
+// shiftGap moves the ends of the gap which are in [lo, hi] by delta, so that the
+// gap keeps its chunks when they are moved inside dq.chunkPitch.
+func (dq *Deque[T]) shiftGap(lo, hi, delta int) {
+	if dq.gapLo >= lo && dq.gapLo <= hi {
+		dq.gapLo += delta
+	}
+	if dq.gapHi >= lo && dq.gapHi <= hi {
+		dq.gapHi += delta
+	}
+}
+
+// Segments returns the values in [from, to) as a list of slices sharing the
+// memory of dq, one slice per chunk, so that the values can be consumed without
+// being copied, e.g. by a vectored write. It returns nil if the range is empty,
+// and it panics if from or to is out of range, or if from > to.
+//
+// The slices are only valid until the next modification of dq. Writing to them
+// modifies the values in dq.
+func (dq *Deque[T]) Segments(from, to int) [][]T {
+	return dq.SegmentsWithBuffer(from, to, nil)
+}
+
+// SegmentsWithBuffer is similar to Segments except that it uses buf to
+// store the slices as long as it has enough space.
+func (dq *Deque[T]) SegmentsWithBuffer(from, to int, buf [][]T) [][]T {
+	if from < 0 || from > dq.count {
+		panic(fmt.Errorf("out of range: %d", from))
+	}
+	if to < from || to > dq.count {
+		panic(fmt.Errorf("out of range: %d", to))
+	}
+	if from == to {
+		return nil
+	}
+
+	j1, k1 := dq.locate(from)
+	j2, k2 := dq.locate(to - 1)
+	n := j2 - j1 + 1
+	if n <= cap(buf) {
+		buf = buf[:n]
+	} else {
+		buf = make([][]T, n)
+	}
+	for j := j1; j <= j2; j++ {
+		c := dq.chunks[j]
+		s, e := c.s, c.e
+		if j == j1 {
+			s = k1
+		}
+		if j == j2 {
+			e = k2 + 1
+		}
+		buf[j-j1] = c.data[s:e:e]
+	}
+	return buf
+}
+
 // Peek returns the value at idx. It panics if idx is out of range.
 func (dq *Deque[T]) Peek(idx int) T {
 	if idx < 0 || idx >= dq.count {
 		panic(fmt.Errorf("out of range: %d", idx))
 	}
 
-	i := idx
-	for _, c := range dq.chunks {
-		n := c.e - c.s
-		if i < n {
-			return c.data[c.s+i]
-		}
-		i -= n
-	}
-
-	panic("impossible")
+	j, k := dq.locate(idx)
+	return dq.chunks[j].data[k]
 }
 
 // Replace replaces the value at idx with v. It panics if idx is out of range.
+//
+// If dq has a byte limit and v does not fit, Replace follows the overflow policy of
+// dq. With DropOldest, values are evicted from the front of dq until it fits.
 func (dq *Deque[T]) Replace(idx int, v T) {
 	if idx < 0 || idx >= dq.count {
 		panic(fmt.Errorf("out of range: %d", idx))
 	}
 
-	i := idx
-	for _, c := range dq.chunks {
-		n := c.e - c.s
-		if i < n {
-			c.data[c.s+i] = v
-			return
-		}
-		i -= n
+	j, k := dq.locate(idx)
+	if dq.sizeOf != nil {
+		dq.replaceAt(j, k, v)
+		return
+	}
+	dq.chunks[j].data[k] = v
+}
+
+// At returns a pointer to the value at idx. It panics if idx is out of range.
+//
+// The pointer stays valid only until the next call that removes values from dq,
+// adds values anywhere other than at the two ends of dq, or reorders or moves
+// the values of dq. After that, the memory of the chunk holding the value may
+// have been recycled and reused by later values, or the pointer may point at a
+// different value.
+func (dq *Deque[T]) At(idx int) *T {
+	if idx < 0 || idx >= dq.count {
+		panic(fmt.Errorf("out of range: %d", idx))
 	}
 
-	panic("impossible")
+	j, k := dq.locate(idx)
+	return &dq.chunks[j].data[k]
+}
+
+// FrontPtr returns a pointer to the first value of dq, or nil if dq is empty.
+// See At for how long the pointer stays valid.
+func (dq *Deque[T]) FrontPtr() *T {
+	if dq.count == 0 {
+		return nil
+	}
+	c := dq.chunks[0]
+	return &c.data[c.s]
+}
+
+// BackPtr returns a pointer to the last value of dq, or nil if dq is empty.
+// See At for how long the pointer stays valid.
+func (dq *Deque[T]) BackPtr() *T {
+	if dq.count == 0 {
+		return nil
+	}
+	c := dq.chunks[len(dq.chunks)-1]
+	return &c.data[c.e-1]
 }
 
 // Swap exchanges the two values at idx1 and idx2. It panics if idx1 or idx2 is out of range.
@@ -488,37 +1348,21 @@ func (dq *Deque[T]) Swap(idx1, idx2 int) {
 		panic(fmt.Errorf("out of range: %d", idx2))
 	}
 
-	i1, i2 := idx1, idx2
-	var p1, p2 *T
-	for _, c := range dq.chunks {
-		n := c.e - c.s
-		if p1 == nil {
-			if i1 < n {
-				p1 = &c.data[c.s+i1]
-			} else {
-				i1 -= n
-			}
-		}
-		if p2 == nil {
-			if i2 < n {
-				p2 = &c.data[c.s+i2]
-			} else {
-				i2 -= n
-			}
-		}
-		if p1 != nil && p2 != nil {
-			*p1, *p2 = *p2, *p1
-			return
-		}
-	}
-
-	panic("impossible")
+	j1, k1 := dq.locate(idx1)
+	j2, k2 := dq.locate(idx2)
+	p1 := &dq.chunks[j1].data[k1]
+	p2 := &dq.chunks[j2].data[k2]
+	*p1, *p2 = *p2, *p1
 }
 
 // Insert inserts a new value v before the value at idx.
 //
 // Insert may cause the split of a chunk inside dq. Because the size of a chunk is fixed,
 // the amount of time taken by Insert has a reasonable limit.
+//
+// If dq has a max length and is full, or v does not fit in the byte budget of dq,
+// Insert follows the overflow policy of dq. Insert works like PushFront if idx <= 0,
+// and like PushBack if idx >= dq.Len().
 func (dq *Deque[T]) Insert(idx int, v T) {
 	if idx <= 0 {
 		dq.PushFront(v)
@@ -528,19 +1372,27 @@ func (dq *Deque[T]) Insert(idx int, v T) {
 		dq.PushBack(v)
 		return
 	}
-
-	i := idx
-	for j, c := range dq.chunks {
-		n := c.e - c.s
-		if i < n {
-			dq.insertImpl(i, v, j, c)
-			dq.count++
+	if dq.sizeOf != nil {
+		dq.insertSized(idx, v)
+		return
+	}
+	if dq.count >= dq.maxLen {
+		if !dq.makeRoom(v, false) {
 			return
 		}
-		i -= n
+		idx--
 	}
 
-	panic("impossible")
+	j, k := dq.locate(idx)
+	dq.insertAt(j, k, v)
+}
+
+func (dq *Deque[T]) insertAt(j, k int, v T) {
+	c := dq.chunks[j]
+	dq.insertImpl(k-c.s, v, j, c)
+	dq.reindex(j-1, j+1)
+	dq.count++
+	dq.watch()
 }
 
 func (dq *Deque[T]) insertImpl(i int, v T, j int, c *chunk[T]) {
@@ -646,6 +1498,7 @@ func (dq *Deque[T]) insertNewChunk(j int, before bool) *chunk[T] {
 		if f := dq.sFree; f == 0 {
 			dq.realloc()
 		}
+		dq.shiftGap(math.MinInt, dq.sFree+j-1, -1)
 		dq.sFree--
 		pitchLen := len(dq.chunkPitch)
 		a := dq.chunkPitch[dq.sFree : pitchLen-dq.eFree]
@@ -656,6 +1509,7 @@ func (dq *Deque[T]) insertNewChunk(j int, before bool) *chunk[T] {
 		if f := dq.eFree; f == 0 {
 			dq.realloc()
 		}
+		dq.shiftGap(dq.sFree+j+1, math.MaxInt, 1)
 		dq.eFree--
 		pitchLen := len(dq.chunkPitch)
 		a := dq.chunkPitch[dq.sFree : pitchLen-dq.eFree]
@@ -666,24 +1520,233 @@ func (dq *Deque[T]) insertNewChunk(j int, before bool) *chunk[T] {
 	return cc
 }
 
+// insertNewChunks inserts n new chunks after dq.chunks[j] and returns them.
+func (dq *Deque[T]) insertNewChunks(j int, n int) []*chunk[T] {
+	dq.reserve(n)
+	dq.shiftGap(dq.sFree+j+1, math.MaxInt, n)
+	dq.eFree -= n
+	pitchLen := len(dq.chunkPitch)
+	a := dq.chunkPitch[dq.sFree : pitchLen-dq.eFree]
+	copy(a[j+1+n:], dq.chunks[j+1:])
+	for i := j + 1; i <= j+n; i++ {
+		cc := dq.chunkPool.Get().(*chunk[T])
+		cc.s, cc.e = 0, 0
+		a[i] = cc
+	}
+	dq.chunks = a
+	return a[j+1 : j+1+n]
+}
+
+// InsertSlice inserts all the values in vs before the value at idx, keeping their order.
+//
+// Instead of shifting the values one by one, InsertSlice splits the chunk holding idx
+// and splices new chunks filled with vs in between.
+//
+// If dq has a max length or a byte limit, InsertSlice follows the overflow policy
+// of dq. InsertSlice works like PushFrontSlice if idx <= 0, and like PushBackSlice
+// if idx >= dq.Len().
+func (dq *Deque[T]) InsertSlice(idx int, vs []T) {
+	if len(vs) == 0 {
+		return
+	}
+	if idx <= 0 {
+		dq.PushFrontSlice(vs)
+		return
+	}
+	if idx >= dq.count {
+		dq.PushBackSlice(vs)
+		return
+	}
+	if dq.sizeOf != nil {
+		if vs = dq.admitAt(vs); len(vs) > 0 {
+			dq.insertSlice(idx, vs)
+			dq.trimFront()
+			dq.watch()
+		}
+		return
+	}
+	if excess := dq.count + len(vs) - dq.maxLen; excess > 0 {
+		if dq.policy != DropOldest {
+			vs = dq.admit(vs, false)
+		} else {
+			n := minInt(excess, idx)
+			dq.evictFront(n)
+			idx -= n
+			excess -= n
+			n = minInt(excess, len(vs))
+			dq.evictValues(vs[:n], false)
+			vs = vs[n:]
+			dq.evictFront(excess - n)
+		}
+		if len(vs) == 0 {
+			return
+		}
+		if idx == 0 {
+			dq.pushFrontSlice(vs)
+			dq.watch()
+			return
+		}
+	}
+	dq.insertSlice(idx, vs)
+	dq.watch()
+}
+
+func (dq *Deque[T]) insertSlice(idx int, vs []T) {
+	j, k := dq.locate(idx)
+	c := dq.chunks[j]
+	dq.count += len(vs)
+	if len(vs) <= dq.chunkSize-c.e {
+		copy(c.data[k+len(vs):], c.data[k:c.e])
+		copy(c.data[k:], vs)
+		c.e += len(vs)
+		dq.reindex(j, j)
+		return
+	}
+
+	// The values after idx go to a new tail chunk. vs fills the rest of c first,
+	// then the free space at the front of the tail chunk, then the new chunks
+	// between the two.
+	m := c.e - k
+	x := minInt(len(vs), dq.chunkSize-k)
+	put := minInt(len(vs)-x, dq.chunkSize-m)
+	cnt := (len(vs) - x - put + dq.chunkSize - 1) / dq.chunkSize
+	a := dq.insertNewChunks(j, cnt+1)
+
+	t := a[cnt]
+	t.s, t.e = dq.chunkSize-m, dq.chunkSize
+	copy(t.data[t.s:], c.data[k:c.e])
+	var defVal T
+	for i := k; i < c.e; i++ {
+		c.data[i] = defVal
+	}
+	c.e = k + copy(c.data[k:], vs[:x])
+	t.s -= put
+	copy(t.data[t.s:], vs[len(vs)-put:])
+	vs = vs[x : len(vs)-put]
+	for _, cc := range a[:cnt] {
+		cc.s, cc.e = 0, copy(cc.data, vs)
+		vs = vs[cc.e:]
+	}
+
+	dq.mergeChunks(j + cnt + 1)
+	dq.mergeChunks(j)
+	dq.mergeChunks(j - 1)
+	dq.reindex(j-1, j+cnt+2)
+}
+
+// RemoveRange removes the values in [from, to). It panics if from or to is out of range,
+// or if from > to.
+//
+// Instead of removing the values one by one, RemoveRange drops the chunks completely
+// inside the range and only shifts the values in the two boundary chunks.
+func (dq *Deque[T]) RemoveRange(from, to int) {
+	if from < 0 || from > dq.count {
+		panic(fmt.Errorf("out of range: %d", from))
+	}
+	if to < from || to > dq.count {
+		panic(fmt.Errorf("out of range: %d", to))
+	}
+	if from == to {
+		return
+	}
+	if from == 0 && to == dq.count {
+		dq.Clear()
+		return
+	}
+	if dq.sizeOf != nil {
+		dq.bytes -= dq.sizeOfRange(from, to)
+	}
+
+	j1, k1 := dq.locate(from)
+	j2, k2 := dq.locate(to - 1)
+	dq.count -= to - from
+	if j1 == j2 {
+		dq.removeRangeInChunk(j1, k1, k2+1)
+	} else {
+		dq.removeRangeChunks(j1, k1, j2, k2+1)
+	}
+	dq.watch()
+}
+
+// removeRangeInChunk removes the values in [k1, k2) of dq.chunks[j], shifting
+// whichever side of the range is shorter.
+func (dq *Deque[T]) removeRangeInChunk(j, k1, k2 int) {
+	var defVal T
+	c := dq.chunks[j]
+	if k1-c.s < c.e-k2 {
+		copy(c.data[c.s+k2-k1:], c.data[c.s:k1])
+		for i := c.s; i < c.s+k2-k1; i++ {
+			c.data[i] = defVal
+		}
+		c.s += k2 - k1
+	} else {
+		copy(c.data[k1:], c.data[k2:c.e])
+		for i := c.e - (k2 - k1); i < c.e; i++ {
+			c.data[i] = defVal
+		}
+		c.e -= k2 - k1
+	}
+	if c.s == c.e {
+		dq.removeChunks(j, j+1)
+	} else {
+		dq.mergeChunks(j)
+	}
+	dq.mergeChunks(j - 1)
+	dq.reindex(j-2, j+1)
+}
+
+// removeRangeChunks removes the values from position k1 of dq.chunks[j1] up to
+// position k2 of dq.chunks[j2], where j1 < j2, dropping the chunks in between.
+func (dq *Deque[T]) removeRangeChunks(j1, k1, j2, k2 int) {
+	var defVal T
+	c1, c2 := dq.chunks[j1], dq.chunks[j2]
+	for i := k1; i < c1.e; i++ {
+		c1.data[i] = defVal
+	}
+	c1.e = k1
+	for i := c2.s; i < k2; i++ {
+		c2.data[i] = defVal
+	}
+	c2.s = k2
+	for _, c := range dq.chunks[j1+1 : j2] {
+		for i := c.s; i < c.e; i++ {
+			c.data[i] = defVal
+		}
+	}
+
+	lo, hi := j1+1, j2
+	if c1.s == c1.e {
+		lo--
+	}
+	if c2.s == c2.e {
+		hi++
+	}
+	dq.removeChunks(lo, hi)
+	dq.mergeChunks(lo)
+	dq.mergeChunks(lo - 1)
+	dq.mergeChunks(lo - 2)
+	dq.reindex(lo-2, lo+1)
+}
+
 // Remove removes the value at idx. It panics if idx is out of range.
 func (dq *Deque[T]) Remove(idx int) {
 	if idx < 0 || idx >= dq.count {
 		panic(fmt.Errorf("out of range: %d", idx))
 	}
 
-	i := idx
-	for j, c := range dq.chunks {
-		n := c.e - c.s
-		if i < n {
-			dq.removeElement(i, j, c)
-			dq.count--
-			return
-		}
-		i -= n
-	}
+	j, k := dq.locate(idx)
+	dq.removeAt(j, k)
+}
 
-	panic("impossible")
+func (dq *Deque[T]) removeAt(j, k int) {
+	c := dq.chunks[j]
+	if dq.sizeOf != nil {
+		dq.bytes -= dq.sizeOf(c.data[k])
+	}
+	dq.removeElement(k-c.s, j, c)
+	dq.reindex(j-1, j+1)
+	dq.count--
+	dq.watch()
 }
 
 func (dq *Deque[T]) removeElement(i, j int, c *chunk[T]) {
@@ -710,11 +1773,13 @@ func (dq *Deque[T]) removeElement(i, j int, c *chunk[T]) {
 func (dq *Deque[T]) removeChunk(j int, c *chunk[T]) {
 	dq.chunkPool.Put(c)
 	if j < len(dq.chunks)-j-1 {
+		dq.shiftGap(math.MinInt, dq.sFree+j, 1)
 		copy(dq.chunks[1:], dq.chunks[:j])
 		dq.chunks[0] = nil
 		dq.chunks = dq.chunks[1:]
 		dq.sFree++
 	} else {
+		dq.shiftGap(dq.sFree+j+1, math.MaxInt, -1)
 		copy(dq.chunks[j:], dq.chunks[j+1:])
 		newLen := len(dq.chunks) - 1
 		dq.chunks[newLen] = nil
@@ -723,6 +1788,42 @@ func (dq *Deque[T]) removeChunk(j int, c *chunk[T]) {
 	}
 }
 
+// removeChunks removes the chunks in [lo, hi) from dq.chunks and puts them back
+// into the pool. The values inside them must have been cleared.
+func (dq *Deque[T]) removeChunks(lo, hi int) {
+	cnt := hi - lo
+	if cnt <= 0 {
+		return
+	}
+	for _, c := range dq.chunks[lo:hi] {
+		dq.chunkPool.Put(c)
+	}
+	if x := dq.sFree + lo; dq.gapLo > x && dq.gapLo < x+cnt {
+		dq.gapLo = x
+	}
+	if x := dq.sFree + lo; dq.gapHi > x && dq.gapHi < x+cnt {
+		dq.gapHi = x
+	}
+	if lo < len(dq.chunks)-hi {
+		dq.shiftGap(math.MinInt, dq.sFree+lo, cnt)
+		copy(dq.chunks[cnt:], dq.chunks[:lo])
+		for i := 0; i < cnt; i++ {
+			dq.chunks[i] = nil
+		}
+		dq.chunks = dq.chunks[cnt:]
+		dq.sFree += cnt
+	} else {
+		dq.shiftGap(dq.sFree+hi, math.MaxInt, -cnt)
+		copy(dq.chunks[lo:], dq.chunks[hi:])
+		newLen := len(dq.chunks) - cnt
+		for i := newLen; i < len(dq.chunks); i++ {
+			dq.chunks[i] = nil
+		}
+		dq.chunks = dq.chunks[:newLen]
+		dq.eFree += cnt
+	}
+}
+
 func (dq *Deque[T]) mergeChunks(j int) {
 	if j < 0 {
 		return
@@ -760,8 +1861,84 @@ func (dq *Deque[T]) mergeChunks(j int) {
 	}
 }
 
+// RemoveFunc removes all the values satisfying pred from dq and returns the number
+// of the removed values. The remaining values are compacted in a single pass and
+// the chunks no longer needed are recycled.
+func (dq *Deque[T]) RemoveFunc(pred func(T) bool) int {
+	n := dq.filter(func(v T) bool {
+		return !pred(v)
+	}, nil)
+	dq.watch()
+	return n
+}
+
+// Retain removes all the values not satisfying pred from dq and returns the number
+// of the removed values. See RemoveFunc for details.
+func (dq *Deque[T]) Retain(pred func(T) bool) int {
+	n := dq.filter(pred, nil)
+	dq.watch()
+	return n
+}
+
+// filter moves the values for which keep returns true toward the front of dq,
+// keeping their order, and packs them so that all the chunks except the first
+// one and the last one are full. The other values are passed to drop if it is
+// not nil, and removed from dq. Their bytes are released only if drop is nil,
+// because Partition adds them back. A nil keep keeps all the values. filter returns
+// the number of the removed values.
+func (dq *Deque[T]) filter(keep func(T) bool, drop func(T)) int {
+	n := len(dq.chunks)
+	if n == 0 {
+		return 0
+	}
+
+	var defVal T
+	var kept int
+	wj := 0
+	w := dq.chunks[0]
+	wk := w.s
+	for j := 0; j < n; j++ {
+		c := dq.chunks[j]
+		for k := c.s; k < c.e; k++ {
+			v := c.data[k]
+			c.data[k] = defVal
+			if keep != nil && !keep(v) {
+				if drop != nil {
+					drop(v)
+				} else if dq.sizeOf != nil {
+					dq.bytes -= dq.sizeOf(v)
+				}
+				continue
+			}
+			if wk == dq.chunkSize {
+				wj++
+				w = dq.chunks[wj]
+				w.s, wk = 0, 0
+			}
+			w.data[wk] = v
+			wk++
+			kept++
+		}
+	}
+	for _, c := range dq.chunks[:wj] {
+		c.e = dq.chunkSize
+	}
+	w.e = wk
+
+	removed := dq.count - kept
+	dq.count = kept
+	if kept == 0 {
+		dq.removeChunks(0, n)
+	} else {
+		dq.removeChunks(wj+1, n)
+		dq.reindex(0, wj)
+	}
+	return removed
+}
+
 // Clear removes all the values from dq.
 func (dq *Deque[T]) Clear() {
+	dq.cancelReservation()
 	var defVal T
 	for _, c := range dq.chunks {
 		for j := c.s; j < c.e; j++ {
@@ -775,6 +1952,9 @@ func (dq *Deque[T]) Clear() {
 
 	dq.chunks = nil
 	dq.count = 0
+	dq.bytes = 0
+	dq.gapLo, dq.gapHi = 0, 0
+	dq.watch()
 
 	dq.sFree = len(dq.chunkPitch) / 2
 	dq.eFree = len(dq.chunkPitch) - dq.sFree
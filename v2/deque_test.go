@@ -68,6 +68,10 @@ func invariant(t *testing.T, dq *Deque[int], opts ...invariantOption) {
 	}
 
 	var count int
+	lo, hi := dq.gap()
+	if dq.gapLo > dq.gapHi {
+		t.Fatal(`dq.gapLo > dq.gapHi`)
+	}
 	for i, c := range dq.chunks {
 		count += c.e - c.s
 		if c.s < 0 || c.s > dq.chunkSize {
@@ -85,6 +89,11 @@ func invariant(t *testing.T, dq *Deque[int], opts ...invariantOption) {
 		if c.e < c.s {
 			t.Fatal(`c.e < c.s`)
 		}
+		if i+1 < len(dq.chunks) && (i+1 < lo || i >= hi) {
+			if next := dq.chunks[i+1]; c.base+c.e != next.base+next.s {
+				t.Fatal(`c.base+c.e != next.base+next.s`)
+			}
+		}
 
 		if !params.skipChunkMerge {
 			if i+1 < len(dq.chunks) {
@@ -105,6 +114,29 @@ func invariant(t *testing.T, dq *Deque[int], opts ...invariantOption) {
 			}
 		}
 	}
+
+	// locate recomputes the bases in the gap, so they are restored afterwards
+	// to keep the gap for the following operations.
+	bases := make([]int, len(dq.chunks))
+	for j, c := range dq.chunks {
+		bases[j] = c.base
+	}
+	gapLo, gapHi := dq.gapLo, dq.gapHi
+	idx, step := 0, count/128+1
+	for j, c := range dq.chunks {
+		for k := c.s; k < c.e; k++ {
+			if idx%step == 0 {
+				if j1, k1 := dq.locate(idx); j1 != j || k1 != k {
+					t.Fatal(`j1 != j || k1 != k`)
+				}
+			}
+			idx++
+		}
+	}
+	for j, c := range dq.chunks {
+		c.base = bases[j]
+	}
+	dq.gapLo, dq.gapHi = gapLo, gapHi
 }
 
 func TestChunkSize(t *testing.T) {
@@ -325,6 +357,7 @@ func TestDeque_shrinkEnd(t *testing.T) {
 		dq.chunkPitch[i] = &chunk[int]{
 			data: make([]int, dq.chunkSize),
 			e:    dq.chunkSize,
+			base: dq.chunkSize * i,
 		}
 	}
 	dq.eFree -= 10
@@ -377,6 +410,7 @@ func TestDeque_shrinkStart(t *testing.T) {
 		dq.chunkPitch[i] = &chunk[int]{
 			data: make([]int, dq.chunkSize),
 			e:    dq.chunkSize,
+			base: dq.chunkSize * i,
 		}
 	}
 	dq.eFree -= 10
@@ -515,6 +549,137 @@ func TestDeque_PushFront(t *testing.T) {
 	}
 }
 
+func TestDeque_PushBackSlice(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 8, 9, 16, 100, 1000} {
+		vs := make([]int, n)
+		for i := range vs {
+			vs[i] = i
+		}
+		dq := NewDeque[int](WithChunkSize(8))
+		dq.PushBackSlice(vs)
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), vs, fmt.Sprintf("n: %d", n), t)
+
+		dq.PushFront(-1)
+		dq.PushBack(-2)
+		dq.PushBackSlice(vs)
+		invariant(t, dq)
+		expected := append(append(append([]int{-1}, vs...), -2), vs...)
+		checkBufs(nil, dq.Dump(), expected, fmt.Sprintf("n: %d", n), t)
+	}
+}
+
+func TestDeque_PushFrontSlice(t *testing.T) {
+	for _, n := range []int{0, 1, 7, 8, 9, 16, 100, 1000} {
+		vs := make([]int, n)
+		for i := range vs {
+			vs[i] = i
+		}
+		dq := NewDeque[int](WithChunkSize(8))
+		dq.PushFrontSlice(vs)
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), vs, fmt.Sprintf("n: %d", n), t)
+
+		dq.PushFront(-1)
+		dq.PushBack(-2)
+		dq.PushFrontSlice(vs)
+		invariant(t, dq)
+		expected := append(append(append(append([]int(nil), vs...), -1), vs...), -2)
+		checkBufs(nil, dq.Dump(), expected, fmt.Sprintf("n: %d", n), t)
+		if n > 0 && dq.Peek(n) != -1 {
+			t.Fatal(`dq.Peek(n) != -1`)
+		}
+	}
+}
+
+func TestNewDequeFromSlice(t *testing.T) {
+	dq1 := NewDequeFromSlice[int](nil)
+	if dq1.Len() != 0 || dq1.Dump() != nil {
+		t.Fatal(`dq1.Len() != 0 || dq1.Dump() != nil`)
+	}
+
+	vs := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}
+	dq2 := NewDequeFromSlice(vs, WithChunkSize(8))
+	invariant(t, dq2)
+	if dq2.chunkSize != 8 || len(dq2.chunks) != 3 {
+		t.Fatal(`dq2.chunkSize != 8 || len(dq2.chunks) != 3`)
+	}
+	checkBufs(nil, dq2.Dump(), vs, "", t)
+}
+
+//gocyclo:ignore
+func TestDeque_ReserveBack(t *testing.T) {
+	for _, total := range []int{0, 1, 7, 8, 9, 20} {
+		for _, n := range []int{0, 1, 2, 7, 8, 9, 17, 30} {
+			for k := 0; k <= n; k++ {
+				dq := NewDeque[int](WithChunkSize(8))
+				var a []int
+				for i := 0; i < total; i++ {
+					dq.PushBack(i)
+					a = append(a, i)
+				}
+
+				segs := dq.ReserveBack(n)
+				var size int
+				for _, seg := range segs {
+					for i := range seg {
+						seg[i] = 1000 + size
+						size++
+					}
+				}
+				if size != n {
+					t.Fatalf("size != n. size: %d, n: %d", size, n)
+				}
+				if dq.Len() != total {
+					t.Fatal(`dq.Len() != total`)
+				}
+
+				dq.CommitBack(k)
+				for i := 0; i < k; i++ {
+					a = append(a, 1000+i)
+				}
+				invariant(t, dq)
+				checkBufs(nil, dq.Dump(), a, fmt.Sprintf("total: %d, n: %d, k: %d", total, n, k), t)
+				if len(dq.reserved) != 0 || dq.nReserved != 0 {
+					t.Fatal(`len(dq.reserved) != 0 || dq.nReserved != 0`)
+				}
+
+				dq.PushBack(-1)
+				if v, ok := dq.Back(); !ok || v != -1 {
+					t.Fatal(`v, ok := dq.Back(); !ok || v != -1`)
+				}
+			}
+		}
+	}
+
+	dq := NewDeque[int](WithChunkSize(8))
+	dq.PushBack(1)
+	for _, seg := range dq.ReserveBack(20) {
+		for i := range seg {
+			seg[i] = 2
+		}
+	}
+	dq.ReserveBack(3)
+	invariant(t, dq)
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		dq.CommitBack(4)
+		t.Fatal("CommitBack should panic")
+	}()
+	dq.Clear()
+	invariant(t, dq)
+	func() {
+		defer func() {
+			_ = recover()
+		}()
+		dq.CommitBack(1)
+		t.Fatal("CommitBack should panic")
+	}()
+	dq.CommitBack(0)
+}
+
 func TestDeque_PopBack(t *testing.T) {
 	dq := NewDeque[int]()
 	if _, ok := dq.TryPopBack(); ok {
@@ -569,6 +734,78 @@ func TestDeque_PopFront(t *testing.T) {
 	}()
 }
 
+func TestDeque_TryPopFrontIf(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	even := func(v int) bool {
+		return v%2 == 0
+	}
+	if _, ok := dq.TryPopFrontIf(even); ok {
+		t.Fatal("ok should be false")
+	}
+	if _, ok := dq.TryPopBackIf(even); ok {
+		t.Fatal("ok should be false")
+	}
+
+	dq.PushBackSlice([]int{2, 4, 5, 6, 7, 8})
+	if v, ok := dq.TryPopFrontIf(even); !ok || v != 2 {
+		t.Fatal(`v, ok := dq.TryPopFrontIf(even); !ok || v != 2`)
+	}
+	if v, ok := dq.TryPopBackIf(even); !ok || v != 8 {
+		t.Fatal(`v, ok := dq.TryPopBackIf(even); !ok || v != 8`)
+	}
+	if _, ok := dq.TryPopBackIf(even); ok {
+		t.Fatal("ok should be false")
+	}
+	invariant(t, dq)
+	checkValues(t, dq, 4, 5, 6, 7)
+}
+
+func TestDeque_PopFrontWhile(t *testing.T) {
+	for _, total := range []int{0, 1, 7, 8, 9, 100} {
+		for _, limit := range []int{0, 1, 7, 8, 9, 50, 100} {
+			for _, useBuf := range []bool{false, true} {
+				dq1 := NewDeque[int](WithChunkSize(8))
+				dq2 := NewDeque[int](WithChunkSize(8))
+				var a []int
+				for i := 0; i < total; i++ {
+					dq1.PushBack(i)
+					dq2.PushFront(i)
+					a = append(a, i)
+				}
+				pred := func(v int) bool {
+					return v < limit
+				}
+				expected := minInt(limit, total)
+				str := fmt.Sprintf("total: %d, limit: %d, useBuf: %v", total, limit, useBuf)
+
+				if useBuf {
+					bufA := make([]int, 0, 16)
+					bufB := dq1.PopFrontWhileWithBuffer(pred, bufA)
+					if expected == 0 && bufB != nil {
+						t.Fatal(`bufB != nil. ` + str)
+					}
+					checkBufs(bufA, bufB, a[:expected], str, t)
+					bufB = dq2.PopBackWhileWithBuffer(pred, bufA)
+					checkBufs(bufA, bufB, a[:expected], str, t)
+				} else {
+					if n := dq1.PopFrontWhile(pred); n != expected {
+						t.Fatalf("unexpected result of PopFrontWhile: %d. %s", n, str)
+					}
+					if n := dq2.PopBackWhile(pred); n != expected {
+						t.Fatalf("unexpected result of PopBackWhile: %d. %s", n, str)
+					}
+				}
+				invariant(t, dq1)
+				invariant(t, dq2)
+				checkBufs(nil, dq1.Dump(), a[expected:], str, t)
+				if dq2.Len() != total-expected {
+					t.Fatal(`dq2.Len() != total-expected. ` + str)
+				}
+			}
+		}
+	}
+}
+
 func TestDeque_DequeueMany(t *testing.T) {
 	dq1 := NewDeque[int]()
 	for i := -1; i <= 1; i++ {
@@ -731,6 +968,195 @@ func TestDeque_DequeueManyWithBuffer(t *testing.T) {
 	}
 }
 
+func TestDeque_PopBackMany(t *testing.T) {
+	dq1 := NewDeque[int]()
+	for i := -1; i <= 1; i++ {
+		if dq1.PopBackMany(i) != nil {
+			t.Fatalf("dq1.PopBackMany(%d) should return nil while dq1 is empty", i)
+		}
+		if dq1.DequeueManyFromBack(i) != nil {
+			t.Fatalf("dq1.DequeueManyFromBack(%d) should return nil while dq1 is empty", i)
+		}
+		invariant(t, dq1)
+	}
+
+	const total = 100
+	whole := make([]int, total)
+	for i := 0; i < total; i++ {
+		whole[i] = i
+	}
+
+	dq2 := NewDeque[int](WithChunkSize(16))
+	steps := []int{0, 1, 2, 3, 5, 7, 8, 15, 16, 17, 31, 32, 33, 47, 48, 49}
+	for i := 0; i < total; i++ {
+		for _, step := range steps {
+			for _, tailFirst := range []bool{true, false} {
+				dq2.PushBackSlice(whole[:i])
+				invariant(t, dq2)
+				remaining := i
+				for remaining > 0 {
+					c := step
+					if step <= 0 || remaining < step {
+						c = remaining
+					}
+					size := dq2.chunkSize * 2 / (i%3 + 1)
+					bufA := make([]int, size)
+					var bufB []int
+					if tailFirst {
+						bufB = dq2.PopBackManyWithBuffer(step, bufA)
+					} else {
+						bufB = dq2.DequeueManyFromBackWithBuffer(step, bufA)
+					}
+					invariant(t, dq2)
+					expected := make([]int, c)
+					copy(expected, whole[remaining-c:remaining])
+					if tailFirst {
+						for j := 0; j < c/2; j++ {
+							expected[j], expected[c-1-j] = expected[c-1-j], expected[j]
+						}
+					}
+					str := fmt.Sprintf("i: %d, step: %d, tailFirst: %v", i, step, tailFirst)
+					checkBufs(bufA, bufB, expected, str, t)
+					remaining -= c
+				}
+				if dq2.Len() != 0 {
+					t.Fatal(`dq2.Len() != 0`)
+				}
+			}
+		}
+	}
+
+	dq3 := NewDequeFromSlice([]int{1, 2, 3, 4, 5})
+	checkBufs(nil, dq3.PopBackMany(2), []int{5, 4}, "", t)
+	checkBufs(nil, dq3.DequeueManyFromBack(2), []int{2, 3}, "", t)
+	checkBufs(nil, dq3.PopBackMany(0), []int{1}, "", t)
+	invariant(t, dq3)
+}
+
+func TestDeque_Rotate(t *testing.T) {
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 33, 100, 1000} {
+		for _, pushFront := range []bool{false, true} {
+			steps := []int{0, 1, -1, 2, -2, 7, -7, 8, -8, 9, -9, 17, -17, 50, -50, 333, -333, 999, -1001}
+			dq := NewDeque[int](WithChunkSize(8))
+			var a []int
+			for i := 0; i < total; i++ {
+				if pushFront {
+					dq.PushFront(i)
+					a = append([]int{i}, a...)
+				} else {
+					dq.PushBack(i)
+					a = append(a, i)
+				}
+			}
+			for _, n := range steps {
+				dq.Rotate(n)
+				if len(a) > 0 {
+					k := n % len(a)
+					if k < 0 {
+						k += len(a)
+					}
+					a = append(a[k:], a[:k]...)
+				}
+				invariant(t, dq)
+				checkBufs(nil, dq.Dump(), a, fmt.Sprintf("total: %d, n: %d", total, n), t)
+			}
+		}
+	}
+
+	dq := NewDeque[int](WithChunkSize(8))
+	for i := 0; i < 10000; i++ {
+		dq.PushBack(i)
+	}
+	for i := 0; i < 100; i++ {
+		dq.Rotate(4321)
+		invariant(t, dq)
+	}
+	if v := dq.Peek(0); v != 4321*100%10000 {
+		t.Fatalf("unexpected front value: %d", v)
+	}
+}
+
+func TestDeque_Discard(t *testing.T) {
+	for _, total := range []int{0, 1, 7, 8, 9, 100} {
+		for _, n := range []int{-1, 0, 1, 7, 8, 9, 50, 100, 101} {
+			dq1 := NewDeque[int](WithChunkSize(8))
+			dq2 := NewDeque[int](WithChunkSize(8))
+			var a []int
+			for i := 0; i < total; i++ {
+				dq1.PushBack(i)
+				dq2.PushBack(i)
+				a = append(a, i)
+			}
+
+			expected := maxInt(minInt(n, total), 0)
+			if r := dq1.DiscardFront(n); r != expected {
+				t.Fatalf("unexpected result: %d. total: %d, n: %d", r, total, n)
+			}
+			invariant(t, dq1)
+			checkBufs(nil, dq1.Dump(), a[expected:], fmt.Sprintf("total: %d, n: %d", total, n), t)
+
+			if r := dq2.DiscardBack(n); r != expected {
+				t.Fatalf("unexpected result: %d. total: %d, n: %d", r, total, n)
+			}
+			invariant(t, dq2)
+			checkBufs(nil, dq2.Dump(), a[:total-expected], fmt.Sprintf("total: %d, n: %d", total, n), t)
+		}
+	}
+}
+
+func TestDeque_Segments(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	if dq.Segments(0, 0) != nil {
+		t.Fatal(`dq.Segments(0, 0) != nil`)
+	}
+	var a []int
+	for i := 0; i < 50; i++ {
+		dq.PushBack(i)
+		a = append(a, i)
+	}
+	for i := 0; i < 5; i++ {
+		dq.PushFront(-i)
+		a = append([]int{-i}, a...)
+	}
+
+	for from := 0; from <= len(a); from++ {
+		for to := from; to <= len(a); to++ {
+			bufA := make([][]int, 3)
+			segs := dq.SegmentsWithBuffer(from, to, bufA)
+			var b []int
+			for _, seg := range segs {
+				if len(seg) == 0 {
+					t.Fatal(`len(seg) == 0`)
+				}
+				if len(seg) != cap(seg) {
+					t.Fatal(`len(seg) != cap(seg)`)
+				}
+				b = append(b, seg...)
+			}
+			checkBufs(nil, b, a[from:to], fmt.Sprintf("from: %d, to: %d", from, to), t)
+			if len(segs) > 0 && len(segs) <= cap(bufA) && &segs[0] != &bufA[0] {
+				t.Fatal(`&segs[0] != &bufA[0]`)
+			}
+		}
+	}
+
+	segs := dq.Segments(10, 30)
+	segs[0][0] = 999
+	if dq.Peek(10) != 999 {
+		t.Fatal(`dq.Peek(10) != 999`)
+	}
+
+	for _, r := range [][2]int{{-1, 0}, {0, dq.Len() + 1}, {1, 0}} {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			dq.Segments(r[0], r[1])
+			t.Fatal("Segments should panic")
+		}()
+	}
+}
+
 func TestDeque_Back(t *testing.T) {
 	dq := NewDeque[int]()
 	if _, ok := dq.Back(); ok {
@@ -865,6 +1291,59 @@ func TestDeque_Replace(t *testing.T) {
 	}
 }
 
+//gocyclo:ignore
+func TestDeque_At(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	if dq.FrontPtr() != nil || dq.BackPtr() != nil {
+		t.Fatal(`dq.FrontPtr() != nil || dq.BackPtr() != nil`)
+	}
+	dq.PushBack(1)
+	dq.PopBack()
+	if dq.FrontPtr() != nil || dq.BackPtr() != nil {
+		t.Fatal(`dq.FrontPtr() != nil || dq.BackPtr() != nil`)
+	}
+
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+	}
+	for i := 0; i < 10; i++ {
+		dq.Remove(rand.Intn(dq.Len()))
+	}
+	for i := 0; i < dq.Len(); i++ {
+		p := dq.At(i)
+		if *p != dq.Peek(i) {
+			t.Fatal(`*p != dq.Peek(i)`)
+		}
+		*p = -i
+		if dq.Peek(i) != -i {
+			t.Fatal(`dq.Peek(i) != -i`)
+		}
+	}
+
+	p1, p2, p3 := dq.FrontPtr(), dq.BackPtr(), dq.At(50)
+	if p1 != dq.At(0) || p2 != dq.At(dq.Len()-1) {
+		t.Fatal(`p1 != dq.At(0) || p2 != dq.At(dq.Len()-1)`)
+	}
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+		dq.PushFront(i)
+	}
+	*p1, *p2, *p3 = 1000, 2000, 3000
+	if dq.Peek(100) != 1000 || dq.Peek(dq.Len()-101) != 2000 || dq.Peek(150) != 3000 {
+		t.Fatal(`pointers should stay valid while values are added at the two ends`)
+	}
+
+	for _, idx := range []int{-1, dq.Len()} {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			dq.At(idx)
+			t.Fatal("At should panic")
+		}()
+	}
+}
+
 func TestDeque_Swap(t *testing.T) {
 	chunkSize := NewDeque[int]().chunkSize
 	for _, n := range []int{1, 100, chunkSize, chunkSize + 1, chunkSize * 2, 1000} {
@@ -920,6 +1399,43 @@ func TestDeque_Swap(t *testing.T) {
 	}
 }
 
+func TestDeque_Peek(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	var a []int
+	for i := 0; i < 1000; i++ {
+		dq.PushBack(i)
+		a = append(a, i)
+	}
+	for i := 0; i < 300; i++ {
+		dq.PushFront(-i)
+		a = append([]int{-i}, a...)
+	}
+	for i := 0; i < 500; i++ {
+		if i%3 == 0 {
+			idx := rand.Intn(len(a))
+			dq.Remove(idx)
+			a = append(a[:idx], a[idx+1:]...)
+		} else {
+			idx := rand.Intn(len(a))
+			dq.Insert(idx, i)
+			a = append(a[:idx], append([]int{i}, a[idx:]...)...)
+		}
+		invariant(t, dq, skipChunkMerge())
+	}
+
+	for idx, v := range a {
+		if dq.Peek(idx) != v {
+			t.Fatalf("dq.Peek(idx) != v. idx: %d", idx)
+		}
+	}
+	for idx := range a {
+		j, k := dq.locate(idx)
+		if c := dq.chunks[j]; k < c.s || k >= c.e {
+			t.Fatalf("k < c.s || k >= c.e. idx: %d", idx)
+		}
+	}
+}
+
 func checkValues(t *testing.T, dq *Deque[int], expected ...int) {
 	t.Helper()
 	a := dq.Dump()
@@ -982,6 +1498,60 @@ func TestDeque_Insert(t *testing.T) {
 	}
 }
 
+func TestDeque_InsertSlice(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 8, 9, 30, 100} {
+		for _, m := range []int{0, 1, 2, 7, 8, 9, 15, 16, 17, 50} {
+			for idx := 0; idx <= n; idx++ {
+				dq := NewDeque[int](WithChunkSize(8))
+				var a []int
+				for i := 0; i < n; i++ {
+					dq.PushBack(i)
+					a = append(a, i)
+				}
+				vs := make([]int, m)
+				for i := range vs {
+					vs[i] = 1000 + i
+				}
+				dq.InsertSlice(idx, vs)
+				a = append(a[:idx], append(vs, a[idx:]...)...)
+				invariant(t, dq)
+				checkBufs(nil, dq.Dump(), a, fmt.Sprintf("n: %d, m: %d, idx: %d", n, m, idx), t)
+			}
+		}
+	}
+}
+
+func TestDeque_RemoveRange(t *testing.T) {
+	for _, n := range []int{1, 5, 8, 9, 30, 100} {
+		for from := 0; from <= n; from++ {
+			for to := from; to <= n; to++ {
+				dq := NewDeque[int](WithChunkSize(8))
+				var a []int
+				for i := 0; i < n; i++ {
+					dq.PushFront(i)
+					a = append([]int{i}, a...)
+				}
+				dq.RemoveRange(from, to)
+				a = append(a[:from], a[to:]...)
+				invariant(t, dq)
+				checkBufs(nil, dq.Dump(), a, fmt.Sprintf("n: %d, from: %d, to: %d", n, from, to), t)
+			}
+		}
+	}
+
+	dq := NewDeque[int]()
+	dq.PushBack(1)
+	for _, r := range [][2]int{{-1, 0}, {0, 2}, {1, 0}} {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			dq.RemoveRange(r[0], r[1])
+			t.Fatal("RemoveRange should panic")
+		}()
+	}
+}
+
 func freeSlots(dq *Deque[int], idx int, sFree, eFree int) {
 	c := dq.chunks[idx]
 	for i := 0; i < sFree; i++ {
@@ -994,6 +1564,7 @@ func freeSlots(dq *Deque[int], idx int, sFree, eFree int) {
 	dq.count -= eFree - (dq.chunkSize - c.e)
 	c.s = sFree
 	c.e = dq.chunkSize - eFree
+	dq.reindex(idx, idx)
 }
 
 //gocyclo:ignore
@@ -1119,12 +1690,14 @@ func TestDeque_insertNewChunk(t *testing.T) {
 	invariant(t, dq)
 
 	dq.insertNewChunk(0, true)
+	dq.reindex(0, 0)
 	invariant(t, dq)
 
 	dq.chunkPitch = dq.chunkPitch[dq.sFree : dq.sFree+len(dq.chunks)]
 	dq.sFree, dq.eFree = 0, 0
 
 	dq.insertNewChunk(len(dq.chunks)-1, false)
+	dq.reindex(len(dq.chunks)-1, len(dq.chunks)-1)
 	invariant(t, dq)
 }
 
@@ -1258,24 +1831,82 @@ func TestDeque_Remove(t *testing.T) {
 	invariant(t, dq)
 }
 
+func TestDeque_RemoveFunc(t *testing.T) {
+	for _, total := range []int{0, 1, 7, 8, 9, 100, 1000} {
+		for _, mod := range []int{1, 2, 3, 10, 1000000} {
+			dq := NewDeque[int](WithChunkSize(8))
+			var a []int
+			for i := 0; i < total; i++ {
+				dq.PushFront(total - i)
+				a = append([]int{total - i}, a...)
+			}
+			pred := func(v int) bool {
+				return v%mod == 0
+			}
+
+			dq1 := NewDequeFromSlice(a, WithChunkSize(8))
+			var expected1, expected2 []int
+			for _, v := range a {
+				if pred(v) {
+					expected2 = append(expected2, v)
+				} else {
+					expected1 = append(expected1, v)
+				}
+			}
+
+			str := fmt.Sprintf("total: %d, mod: %d", total, mod)
+			if n := dq.RemoveFunc(pred); n != len(expected2) {
+				t.Fatalf("unexpected result of RemoveFunc: %d. %s", n, str)
+			}
+			invariant(t, dq)
+			checkBufs(nil, dq.Dump(), expected1, str, t)
+			if dq.Len() != len(expected1) {
+				t.Fatal(`dq.Len() != len(expected1)`)
+			}
+
+			if n := dq1.Retain(pred); n != len(expected1) {
+				t.Fatalf("unexpected result of Retain: %d. %s", n, str)
+			}
+			invariant(t, dq1)
+			checkBufs(nil, dq1.Dump(), expected2, str, t)
+			if dq1.Len() != len(expected2) {
+				t.Fatal(`dq1.Len() != len(expected2)`)
+			}
+
+			dq.PushBack(-1)
+			dq.PushFront(-2)
+			if v, ok := dq.Back(); !ok || v != -1 {
+				t.Fatal(`v, ok := dq.Back(); !ok || v != -1`)
+			}
+			if v, ok := dq.Front(); !ok || v != -2 {
+				t.Fatal(`v, ok := dq.Front(); !ok || v != -2`)
+			}
+			invariant(t, dq)
+		}
+	}
+}
+
 //gocyclo:ignore
 func TestDeque_Random(t *testing.T) {
 	cfg1 := map[string]int{
-		"Clear":       1,
-		"Dequeue":     100,
-		"DequeueMany": 10,
-		"Enqueue":     300,
-		"Insert":      300,
-		"PopBack":     100,
-		"PopFront":    100,
-		"PushBack":    300,
-		"PushFront":   300,
-		"Remove":      100,
-		"Replace":     10,
-		"Swap":        10,
-		"TryDequeue":  100,
-		"TryPopBack":  100,
-		"TryPopFront": 100,
+		"Clear":          1,
+		"Dequeue":        100,
+		"DequeueMany":    10,
+		"Enqueue":        300,
+		"Insert":         300,
+		"Peek":           10,
+		"PopBack":        100,
+		"PopFront":       100,
+		"PushBack":       300,
+		"PushFront":      300,
+		"PushBackSlice":  10,
+		"PushFrontSlice": 10,
+		"Remove":         100,
+		"Replace":        10,
+		"Swap":           10,
+		"TryDequeue":     100,
+		"TryPopBack":     100,
+		"TryPopFront":    100,
 	}
 
 	var keys []string
@@ -1349,6 +1980,32 @@ func TestDeque_Random(t *testing.T) {
 			a = append(a, 0)
 			copy(a[idx+1:], a[idx:len(a)-1])
 			a[idx] = i
+		case "Peek":
+			if len(a) > 0 {
+				idx := r.Intn(len(a))
+				if dq.Peek(idx) != a[idx] {
+					t.Fatalf(`dq.Peek(idx) != a[idx]. i: %d`, i)
+				}
+			}
+		case "InsertSlice":
+			var idx int
+			if len(a) > 0 {
+				idx = r.Intn(len(a) + 1)
+			}
+			vs := make([]int, r.Intn(40))
+			for j := range vs {
+				vs[j] = i + j
+			}
+			dq.InsertSlice(idx, vs)
+			a = append(a[:idx], append(vs, a[idx:]...)...)
+		case "PopBackMany":
+			count := r.Intn(20) + 1
+			dq.PopBackMany(count)
+			if len(a) > count {
+				a = a[:len(a)-count]
+			} else {
+				a = a[:0]
+			}
 		case "PopBack":
 			if len(a) > 0 {
 				dq.PopBack()
@@ -1367,18 +2024,61 @@ func TestDeque_Random(t *testing.T) {
 			a = append(a, i)
 			copy(a[1:], a[:len(a)-1])
 			a[0] = i
+		case "PushBackSlice":
+			vs := make([]int, r.Intn(20))
+			for j := range vs {
+				vs[j] = i + j
+			}
+			dq.PushBackSlice(vs)
+			a = append(a, vs...)
+		case "PushFrontSlice":
+			vs := make([]int, r.Intn(20))
+			for j := range vs {
+				vs[j] = i + j
+			}
+			dq.PushFrontSlice(vs)
+			a = append(vs, a...)
 		case "Remove":
 			if len(a) > 0 {
 				idx := r.Intn(len(a))
 				dq.Remove(idx)
 				a = append(a[:idx], a[idx+1:]...)
 			}
+		case "RemoveFunc":
+			mod := r.Intn(5) + 2
+			dq.RemoveFunc(func(v int) bool {
+				return v%mod == 0
+			})
+			var b []int
+			for _, v := range a {
+				if v%mod != 0 {
+					b = append(b, v)
+				}
+			}
+			a = b
+		case "RemoveRange":
+			if len(a) > 0 {
+				from := r.Intn(len(a))
+				to := from + r.Intn(minInt(len(a)-from, 40)+1)
+				dq.RemoveRange(from, to)
+				a = append(a[:from], a[to:]...)
+			}
 		case "Replace":
 			if len(a) > 0 {
 				idx := r.Intn(len(a))
 				dq.Replace(idx, i)
 				a[idx] = i
 			}
+		case "Rotate":
+			n := r.Intn(100) - 50
+			dq.Rotate(n)
+			if len(a) > 0 {
+				k := n % len(a)
+				if k < 0 {
+					k += len(a)
+				}
+				a = append(a[k:], a[:k]...)
+			}
 		case "Swap":
 			if len(a) > 0 {
 				idx1 := r.Intn(len(a))
@@ -26,3 +26,47 @@ func Example() {
 	// 200
 	// 100
 }
+
+func ExampleDeque_Segments() {
+	dq := NewDeque[byte](WithChunkSize(8))
+	dq.PushBackSlice([]byte("hello, deque segments"))
+	for _, seg := range dq.Segments(0, dq.Len()) {
+		fmt.Printf("%q\n", seg)
+	}
+	dq.DiscardFront(7)
+	fmt.Println(dq.Len())
+
+	// Output:
+	// "hello, d"
+	// "eque seg"
+	// "ments"
+	// 14
+}
+
+func ExampleWithMaxLen() {
+	dq := NewDeque[int](WithMaxLen(3), WithOnEvict(func(v int) {
+		fmt.Println("evicted:", v)
+	}))
+	for i := 1; i <= 5; i++ {
+		dq.PushBack(i)
+	}
+	fmt.Println(dq.Dump())
+
+	// Output:
+	// evicted: 1
+	// evicted: 2
+	// [3 4 5]
+}
+
+func ExampleWithMaxBytes() {
+	dq := NewDeque[string](WithMaxBytes(10, func(s string) int64 {
+		return int64(len(s))
+	}))
+	dq.PushBack("hello")
+	dq.PushBack("world")
+	dq.PushBack("!")
+	fmt.Println(dq.Dump(), dq.Bytes())
+
+	// Output:
+	// [world !] 6
+}
@@ -0,0 +1,109 @@
+//go:build go1.23
+
+package deque
+
+import "iter"
+
+// All returns an iterator over the indexes and values in dq, from front to back.
+// Do NOT add values to dq or remove values from dq during the iteration.
+func (dq *Deque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		var i int
+		for _, c := range dq.chunks {
+			for j := c.s; j < c.e; j++ {
+				if !yield(i, c.data[j]) {
+					return
+				}
+				i++
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in dq, from front to back.
+// Do NOT add values to dq or remove values from dq during the iteration.
+func (dq *Deque[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, c := range dq.chunks {
+			for j := c.s; j < c.e; j++ {
+				if !yield(c.data[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indexes and values in dq, from back to front.
+// Do NOT add values to dq or remove values from dq during the iteration.
+func (dq *Deque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := dq.count - 1
+		for k := len(dq.chunks) - 1; k >= 0; k-- {
+			c := dq.chunks[k]
+			for j := c.e - 1; j >= c.s; j-- {
+				if !yield(i, c.data[j]) {
+					return
+				}
+				i--
+			}
+		}
+	}
+}
+
+// AppendSeq adds the values from seq at the back of dq.
+func (dq *Deque[T]) AppendSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		dq.PushBack(v)
+	}
+}
+
+// All returns an iterator over the indexes and values in rd, from front to back.
+// Do NOT add values to rd or remove values from rd during the iteration.
+func (rd *RingDeque[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < rd.count; i++ {
+			if !yield(i, rd.buf[(rd.head+i)&rd.mask]) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values in rd, from front to back.
+// Do NOT add values to rd or remove values from rd during the iteration.
+func (rd *RingDeque[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < rd.count; i++ {
+			if !yield(rd.buf[(rd.head+i)&rd.mask]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the indexes and values in rd, from back to front.
+// Do NOT add values to rd or remove values from rd during the iteration.
+func (rd *RingDeque[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := rd.count - 1; i >= 0; i-- {
+			if !yield(i, rd.buf[(rd.head+i)&rd.mask]) {
+				return
+			}
+		}
+	}
+}
+
+// AppendSeq adds the values from seq at the back of rd.
+func (rd *RingDeque[T]) AppendSeq(seq iter.Seq[T]) {
+	for v := range seq {
+		rd.PushBack(v)
+	}
+}
+
+// Collect collects the values from seq into a new Deque and returns it.
+func Collect[T any](seq iter.Seq[T], opts ...Option) *Deque[T] {
+	dq := NewDeque[T](opts...)
+	dq.AppendSeq(seq)
+	return dq
+}
@@ -0,0 +1,158 @@
+//go:build go1.23
+
+package deque
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDeque_All(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	for i := range dq.All() {
+		t.Fatalf("unexpected index: %d", i)
+	}
+
+	var a []int
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+		dq.PushFront(-i)
+		a = append([]int{-i}, append(a, i)...)
+	}
+
+	var n int
+	for i, v := range dq.All() {
+		if i != n {
+			t.Fatal(`i != n`)
+		}
+		if v != a[i] {
+			t.Fatal(`v != a[i]`)
+		}
+		n++
+	}
+	if n != len(a) {
+		t.Fatal(`n != len(a)`)
+	}
+
+	n = 0
+	for range dq.All() {
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Fatal(`n != 10`)
+	}
+
+	m := maps.Collect(dq.All())
+	if len(m) != len(a) || m[0] != a[0] || m[len(a)-1] != a[len(a)-1] {
+		t.Fatal(`maps.Collect(dq.All()) returned an unexpected result`)
+	}
+}
+
+func TestDeque_Values(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	if vals := slices.Collect(dq.Values()); len(vals) != 0 {
+		t.Fatal(`len(vals) != 0`)
+	}
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+	}
+	if !slices.Equal(slices.Collect(dq.Values()), dq.Dump()) {
+		t.Fatal(`!slices.Equal(slices.Collect(dq.Values()), dq.Dump())`)
+	}
+
+	var n int
+	for v := range dq.Values() {
+		if v == 50 {
+			break
+		}
+		n++
+	}
+	if n != 50 {
+		t.Fatal(`n != 50`)
+	}
+}
+
+func TestDeque_Backward(t *testing.T) {
+	dq := NewDeque[int](WithChunkSize(8))
+	for i := range dq.Backward() {
+		t.Fatalf("unexpected index: %d", i)
+	}
+	for i := 0; i < 100; i++ {
+		dq.PushBack(i)
+	}
+	dq.Insert(50, -1)
+	a := dq.Dump()
+
+	n := len(a)
+	for i, v := range dq.Backward() {
+		n--
+		if i != n {
+			t.Fatal(`i != n`)
+		}
+		if v != a[i] {
+			t.Fatal(`v != a[i]`)
+		}
+	}
+	if n != 0 {
+		t.Fatal(`n != 0`)
+	}
+
+	for i := range dq.Backward() {
+		if i != len(a)-1 {
+			t.Fatal(`i != len(a)-1`)
+		}
+		break
+	}
+}
+
+func TestCollect(t *testing.T) {
+	a := make([]int, 1000)
+	for i := range a {
+		a[i] = i
+	}
+	dq := Collect(slices.Values(a), WithChunkSize(16))
+	invariant(t, dq)
+	if dq.chunkSize != 16 {
+		t.Fatal(`dq.chunkSize != 16`)
+	}
+	if !slices.Equal(dq.Dump(), a) {
+		t.Fatal(`!slices.Equal(dq.Dump(), a)`)
+	}
+
+	dq.AppendSeq(slices.Values([]int{1000, 1001}))
+	invariant(t, dq)
+	if dq.Len() != 1002 || dq.Peek(1001) != 1001 {
+		t.Fatal(`dq.Len() != 1002 || dq.Peek(1001) != 1001`)
+	}
+}
+
+func TestRingDeque_Iterators(t *testing.T) {
+	rd := NewRingDeque[int](8)
+	rd.PushBackSlice([]int{3, 4, 5, 6})
+	rd.PushFrontSlice([]int{1, 2})
+
+	checkBufs(nil, slices.Collect(rd.Values()), []int{1, 2, 3, 4, 5, 6}, "", t)
+	var idx, vals []int
+	for i, v := range rd.All() {
+		if i == 3 {
+			break
+		}
+		idx, vals = append(idx, i), append(vals, v)
+	}
+	checkBufs(nil, idx, []int{0, 1, 2}, "", t)
+	checkBufs(nil, vals, []int{1, 2, 3}, "", t)
+
+	idx, vals = nil, nil
+	for i, v := range rd.Backward() {
+		idx, vals = append(idx, i), append(vals, v)
+	}
+	checkBufs(nil, idx, []int{5, 4, 3, 2, 1, 0}, "", t)
+	checkBufs(nil, vals, []int{6, 5, 4, 3, 2, 1}, "", t)
+
+	rd.AppendSeq(slices.Values([]int{7, 8, 9}))
+	checkBufs(nil, rd.Dump(), []int{2, 3, 4, 5, 6, 7, 8, 9}, "", t)
+}
@@ -0,0 +1,10 @@
+//go:build go1.21
+
+package deque
+
+import "cmp"
+
+// Sort sorts the values in dq in ascending order. See Deque.SortFunc for details.
+func Sort[T cmp.Ordered](dq *Deque[T]) {
+	dq.SortFunc(cmp.Compare[T])
+}
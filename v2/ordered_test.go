@@ -0,0 +1,21 @@
+//go:build go1.21
+
+package deque
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	dq := NewDeque[float64](WithChunkSize(8))
+	a := []float64{3, math.NaN(), 1, -2, math.Inf(1), 0, 5, 4, 1, 9, 8, 7, 6}
+	dq.PushBackSlice(a)
+	Sort(dq)
+	slices.Sort(a)
+	b := dq.Dump()
+	if len(a) != len(b) || !math.IsNaN(b[0]) || !slices.Equal(a[1:], b[1:]) {
+		t.Fatalf("unexpected result: %v", b)
+	}
+}
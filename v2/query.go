@@ -0,0 +1,115 @@
+package deque
+
+// IndexFunc returns the index of the first value satisfying f, or -1 if none do.
+func (dq *Deque[T]) IndexFunc(f func(T) bool) int {
+	var i int
+	for _, c := range dq.chunks {
+		for j := c.s; j < c.e; j++ {
+			if f(c.data[j]) {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+// LastIndexFunc returns the index of the last value satisfying f, or -1 if none do.
+func (dq *Deque[T]) LastIndexFunc(f func(T) bool) int {
+	i := dq.count - 1
+	for k := len(dq.chunks) - 1; k >= 0; k-- {
+		c := dq.chunks[k]
+		for j := c.e - 1; j >= c.s; j-- {
+			if f(c.data[j]) {
+				return i
+			}
+			i--
+		}
+	}
+	return -1
+}
+
+// ContainsFunc returns whether at least one value in dq satisfies f.
+func (dq *Deque[T]) ContainsFunc(f func(T) bool) bool {
+	return dq.IndexFunc(f) >= 0
+}
+
+// CountFunc returns the number of the values in dq satisfying f.
+func (dq *Deque[T]) CountFunc(f func(T) bool) int {
+	var n int
+	for _, c := range dq.chunks {
+		for j := c.s; j < c.e; j++ {
+			if f(c.data[j]) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// EqualFunc returns whether dq and other have the same length and eq returns
+// true for each pair of values at the same index.
+func (dq *Deque[T]) EqualFunc(other *Deque[T], eq func(a, b T) bool) bool {
+	if dq.count != other.count {
+		return false
+	}
+	if dq.count == 0 {
+		return true
+	}
+
+	j2 := 0
+	c2 := other.chunks[0]
+	k2 := c2.s
+	for _, c1 := range dq.chunks {
+		for k1 := c1.s; k1 < c1.e; k1++ {
+			if k2 == c2.e {
+				j2++
+				c2 = other.chunks[j2]
+				k2 = c2.s
+			}
+			if !eq(c1.data[k1], c2.data[k2]) {
+				return false
+			}
+			k2++
+		}
+	}
+	return true
+}
+
+// Index returns the index of the first occurrence of v in dq, or -1 if not present.
+func Index[T comparable](dq *Deque[T], v T) int {
+	return dq.IndexFunc(func(x T) bool {
+		return x == v
+	})
+}
+
+// Contains returns whether v is present in dq.
+func Contains[T comparable](dq *Deque[T], v T) bool {
+	return Index(dq, v) >= 0
+}
+
+// Equal returns whether dq1 and dq2 have the same length and all the values
+// at the same index are equal. Floating point NaNs are not considered equal.
+func Equal[T comparable](dq1, dq2 *Deque[T]) bool {
+	return dq1.EqualFunc(dq2, func(a, b T) bool {
+		return a == b
+	})
+}
+
+// Compact replaces consecutive runs of equal values in dq with a single copy,
+// like the uniq command found on Unix. It returns the number of the removed
+// values.
+func Compact[T comparable](dq *Deque[T]) int {
+	var prev T
+	first := true
+	n := dq.filter(func(v T) bool {
+		if !first && v == prev {
+			return false
+		}
+		first = false
+		prev = v
+		return true
+	}, nil)
+	dq.watch()
+	return n
+}
@@ -0,0 +1,112 @@
+package deque
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDeque_IndexFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		for v := -1; v <= total/2+1; v++ {
+			first, last, count := -1, -1, 0
+			for i, x := range a {
+				if x == v {
+					if first < 0 {
+						first = i
+					}
+					last = i
+					count++
+				}
+			}
+			eq := func(x int) bool {
+				return x == v
+			}
+			str := fmt.Sprintf("total: %d, v: %d", total, v)
+			if i := dq.IndexFunc(eq); i != first {
+				t.Fatalf("unexpected result of IndexFunc: %d. %s", i, str)
+			}
+			if i := Index(dq, v); i != first {
+				t.Fatalf("unexpected result of Index: %d. %s", i, str)
+			}
+			if i := dq.LastIndexFunc(eq); i != last {
+				t.Fatalf("unexpected result of LastIndexFunc: %d. %s", i, str)
+			}
+			if n := dq.CountFunc(eq); n != count {
+				t.Fatalf("unexpected result of CountFunc: %d. %s", n, str)
+			}
+			if ok := dq.ContainsFunc(eq); ok != (count > 0) {
+				t.Fatalf("unexpected result of ContainsFunc: %v. %s", ok, str)
+			}
+			if ok := Contains(dq, v); ok != (count > 0) {
+				t.Fatalf("unexpected result of Contains: %v. %s", ok, str)
+			}
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq1, a := newRandomDeque(r, total)
+		dq2 := NewDeque[int](WithChunkSize(16))
+		for i := len(a) - 1; i >= 0; i-- {
+			dq2.PushFront(a[i])
+		}
+		if !Equal(dq1, dq2) || !Equal(dq2, dq1) {
+			t.Fatalf("dq1 and dq2 should be equal. total: %d", total)
+		}
+		if len(a) == 0 {
+			continue
+		}
+
+		dq2.Replace(len(a)-1, -1)
+		if Equal(dq1, dq2) {
+			t.Fatalf("dq1 and dq2 should not be equal. total: %d", total)
+		}
+		dq2.PopBack()
+		if Equal(dq1, dq2) {
+			t.Fatalf("dq1 and dq2 should not be equal. total: %d", total)
+		}
+	}
+
+	dq3 := NewDequeFromSlice([]float64{1, math.NaN()})
+	dq4 := NewDequeFromSlice([]float64{1, math.NaN()})
+	if Equal(dq3, dq4) {
+		t.Fatal(`Equal(dq3, dq4)`)
+	}
+	if !dq3.EqualFunc(dq4, func(a, b float64) bool {
+		return a == b || math.IsNaN(a) && math.IsNaN(b)
+	}) {
+		t.Fatal(`!dq3.EqualFunc(dq4, ...)`)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		for i := range a {
+			a[i] %= 3
+			dq.Replace(i, a[i])
+		}
+
+		var expected []int
+		for i, v := range a {
+			if i == 0 || v != a[i-1] {
+				expected = append(expected, v)
+			}
+		}
+		if n := Compact(dq); n != len(a)-len(expected) {
+			t.Fatalf("unexpected result: %d. total: %d", n, total)
+		}
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), expected, fmt.Sprintf("total: %d", total), t)
+		if dq.Len() != len(expected) {
+			t.Fatal(`dq.Len() != len(expected)`)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package deque
+
+import (
+	"fmt"
+)
+
+// Reverse reverses the order of the values in dq in place. It reverses the order
+// of the chunks and mirrors the values inside every chunk, so no chunk is
+// allocated or released.
+func (dq *Deque[T]) Reverse() {
+	dq.cancelReservation()
+	n := len(dq.chunks)
+	if n == 0 {
+		return
+	}
+
+	for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+		dq.chunks[i], dq.chunks[j] = dq.chunks[j], dq.chunks[i]
+	}
+	for _, c := range dq.chunks {
+		lo := minInt(c.s, dq.chunkSize-c.e)
+		for i, j := lo, dq.chunkSize-1-lo; i < j; i, j = i+1, j-1 {
+			c.data[i], c.data[j] = c.data[j], c.data[i]
+		}
+		c.s, c.e = dq.chunkSize-c.e, dq.chunkSize-c.s
+	}
+	dq.reindex(0, n-1)
+}
+
+// ReverseView is a read-only view of a Deque, which presents the values of the
+// Deque in the opposite order without copying them.
+type ReverseView[T any] struct {
+	dq *Deque[T]
+}
+
+// Reversed returns a read-only view of dq in the opposite order. The view reflects
+// the later modifications of dq.
+func (dq *Deque[T]) Reversed() ReverseView[T] {
+	return ReverseView[T]{dq: dq}
+}
+
+// Front returns the first value of the view, i.e. the last value of the Deque,
+// if any. The return value ok indicates whether it succeeded.
+func (rv ReverseView[T]) Front() (_ T, ok bool) {
+	return rv.dq.Back()
+}
+
+// Back returns the last value of the view, i.e. the first value of the Deque,
+// if any. The return value ok indicates whether it succeeded.
+func (rv ReverseView[T]) Back() (_ T, ok bool) {
+	return rv.dq.Front()
+}
+
+// IsEmpty returns whether the view is empty.
+func (rv ReverseView[T]) IsEmpty() bool {
+	return rv.dq.count == 0
+}
+
+// Len returns the number of values in the view.
+func (rv ReverseView[T]) Len() int {
+	return rv.dq.count
+}
+
+// Peek returns the value at idx of the view. It panics if idx is out of range.
+func (rv ReverseView[T]) Peek(idx int) T {
+	n := rv.dq.count
+	if idx < 0 || idx >= n {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+	return rv.dq.Peek(n - 1 - idx)
+}
+
+// Range iterates all the values in the view, i.e. from the back of the Deque to
+// the front. Do NOT add values to the Deque or remove values from the Deque during Range.
+func (rv ReverseView[T]) Range(f func(i int, v T) bool) {
+	var i int
+	for k := len(rv.dq.chunks) - 1; k >= 0; k-- {
+		c := rv.dq.chunks[k]
+		for j := c.e - 1; j >= c.s; j-- {
+			if !f(i, c.data[j]) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// Dump returns all the values in the view.
+func (rv ReverseView[T]) Dump() []T {
+	vals := rv.dq.Dump()
+	for i, j := 0, len(vals)-1; i < j; i, j = i+1, j-1 {
+		vals[i], vals[j] = vals[j], vals[i]
+	}
+	return vals
+}
@@ -0,0 +1,106 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func reverseInts(a []int) []int {
+	b := make([]int, len(a))
+	for i, v := range a {
+		b[len(a)-1-i] = v
+	}
+	return b
+}
+
+func TestDeque_Reverse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		str := fmt.Sprintf("total: %d", total)
+		dq.Reverse()
+		invariant(t, dq, skipChunkMerge())
+		if dq.Len() != len(a) {
+			t.Fatalf("dq.Len() != len(a). %s", str)
+		}
+		b := reverseInts(a)
+		checkValues(t, dq, b...)
+		for i := 0; i < len(b); i += 1 + i/4 {
+			if dq.Peek(i) != b[i] {
+				t.Fatalf("dq.Peek(i) != b[i]. %s", str)
+			}
+		}
+
+		dq.PushFront(-1)
+		dq.PushBack(-2)
+		dq.Insert(dq.Len()/2, -3)
+		invariant(t, dq, skipChunkMerge())
+		dq.Reverse()
+		invariant(t, dq, skipChunkMerge())
+		if v, _ := dq.Front(); v != -2 {
+			t.Fatalf("v != -2. %s", str)
+		}
+		if v, _ := dq.Back(); v != -1 {
+			t.Fatalf("v != -1. %s", str)
+		}
+	}
+}
+
+func TestDeque_Reversed(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100} {
+		dq, a := newRandomDeque(r, total)
+		str := fmt.Sprintf("total: %d", total)
+		rv := dq.Reversed()
+		b := reverseInts(a)
+		if rv.Len() != len(b) || rv.IsEmpty() != (len(b) == 0) {
+			t.Fatalf("unexpected length of rv. %s", str)
+		}
+		checkBufs(nil, rv.Dump(), b, str, t)
+		for i, v := range b {
+			if rv.Peek(i) != v {
+				t.Fatalf("rv.Peek(i) != v. %s", str)
+			}
+		}
+		var c []int
+		rv.Range(func(i int, v int) bool {
+			if i != len(c) {
+				t.Fatalf("i != len(c). %s", str)
+			}
+			c = append(c, v)
+			return true
+		})
+		checkBufs(nil, c, b, str, t)
+
+		front, ok1 := rv.Front()
+		back, ok2 := rv.Back()
+		if len(b) == 0 {
+			if ok1 || ok2 {
+				t.Fatalf("ok1 || ok2. %s", str)
+			}
+			continue
+		}
+		if front != b[0] || back != b[len(b)-1] {
+			t.Fatalf("front != b[0] || back != b[len(b)-1]. %s", str)
+		}
+
+		var n int
+		rv.Range(func(i int, v int) bool {
+			n++
+			return i < 2
+		})
+		if n != minInt(3, len(b)) {
+			t.Fatalf("n != minInt(3, len(b)). %s", str)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`Peek should panic`)
+			}
+		}()
+		NewDequeFromSlice([]int{1, 2, 3}).Reversed().Peek(3)
+	}()
+}
@@ -0,0 +1,1169 @@
+package deque
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RingDeque is a double-ended queue backed by a single ring buffer, whose size
+// is a power of two. Compared with Deque, it has no chunk to look up and no pool
+// to go through, so it suits small and bounded queues better. Peek and Replace
+// take O(1) time.
+//
+// By default, a RingDeque has a fixed capacity. When it is full, what happens to
+// new values depends on its overflow policy, which is DropOldest by default, so
+// it works as a classic ring buffer. With WithGrowth, it doubles its capacity
+// instead, until it reaches the max length set by WithMaxLen, if any.
+//
+// RingDeque has the methods of Deque except the following ones:
+//   - ReserveBack and CommitBack, because the reserved space of a ring buffer may
+//     wrap around its end, so it cannot be returned as one slice.
+//   - CursorAt, because Cursor is built on the chunks of Deque. Peek, Insert and
+//     Remove by index serve the same purpose on a RingDeque.
+//   - Reversed, because ReverseView is a view of a Deque. Use Reverse instead.
+//   - Bytes, because WithMaxBytes does not take effect on a RingDeque.
+type RingDeque[T any] struct {
+	buf   []T
+	mask  int
+	head  int
+	count int
+	limit int
+
+	grow    bool
+	maxLen  int
+	policy  OverflowPolicy
+	onEvict func(T)
+}
+
+// NewRingDeque creates a new RingDeque instance, whose capacity is capacity rounded
+// up to a power of two. Only WithMaxLen, WithOverflowPolicy, WithOnEvict and
+// WithGrowth take effect on a RingDeque.
+func NewRingDeque[T any](capacity int, opts ...Option) *RingDeque[T] {
+	var holder optionHolder
+	for _, opt := range opts {
+		opt(&holder)
+	}
+
+	rd := &RingDeque[T]{
+		grow:   holder.growth,
+		maxLen: maxLenUnlimited,
+		policy: holder.policy,
+	}
+	if holder.maxLen > 0 {
+		rd.maxLen = holder.maxLen
+	}
+	if holder.onEvict != nil {
+		f, ok := holder.onEvict.(func(T))
+		if !ok {
+			panic(fmt.Errorf("the callback of WithOnEvict should be %T, not %T", f, holder.onEvict))
+		}
+		rd.onEvict = f
+	}
+
+	n := 1
+	for n < capacity {
+		n <<= 1
+	}
+	rd.resize(n)
+	return rd
+}
+
+// WithGrowth makes a RingDeque double its capacity when it is full, instead of
+// following its overflow policy. It has no effect on Deque.
+func WithGrowth() Option {
+	return func(holder *optionHolder) {
+		holder.growth = true
+	}
+}
+
+func (rd *RingDeque[T]) resize(n int) {
+	buf := make([]T, n)
+	rd.copyTo(buf, 0, rd.count)
+	rd.buf = buf
+	rd.mask = n - 1
+	rd.head = 0
+	rd.limit = minInt(n, rd.maxLen)
+}
+
+// reserve grows rd, if growth is allowed, so that it can hold n values without
+// exceeding its max length.
+func (rd *RingDeque[T]) reserve(n int) {
+	if !rd.grow || n <= rd.limit {
+		return
+	}
+	size := len(rd.buf)
+	for size < n && size < rd.maxLen {
+		size <<= 1
+	}
+	if size > len(rd.buf) {
+		rd.resize(size)
+	}
+}
+
+// copyTo copies the values in [from, to) to dst.
+func (rd *RingDeque[T]) copyTo(dst []T, from, to int) {
+	if from >= to {
+		return
+	}
+	s := (rd.head + from) & rd.mask
+	if n := copy(dst, rd.buf[s:minInt(s+to-from, len(rd.buf))]); n < to-from {
+		copy(dst[n:], rd.buf[:to-from-n])
+	}
+}
+
+// zero clears the values in [from, to).
+func (rd *RingDeque[T]) zero(from, to int) {
+	var defVal T
+	for i := from; i < to; i++ {
+		rd.buf[(rd.head+i)&rd.mask] = defVal
+	}
+}
+
+// makeRoom is called when v is about to be added to rd, which is full. It either
+// grows rd or follows the overflow policy of rd, and returns whether v should
+// still be added.
+func (rd *RingDeque[T]) makeRoom(v T, front bool) bool {
+	if rd.grow && rd.count < rd.maxLen {
+		rd.resize(len(rd.buf) * 2)
+		return true
+	}
+
+	switch rd.policy {
+	case DropOldest:
+		var old T
+		if front {
+			old = rd.PopBack()
+		} else {
+			old = rd.PopFront()
+		}
+		if rd.onEvict != nil {
+			rd.onEvict(old)
+		}
+		return true
+	case DropNewest:
+		if rd.onEvict != nil {
+			rd.onEvict(v)
+		}
+		return false
+	case Reject:
+		return false
+	default:
+		panic(errFull)
+	}
+}
+
+func (rd *RingDeque[T]) evictFront(n int) {
+	rd.dropFront(rd, n)
+}
+
+// evictValues passes vs to the OnEvict callback of rd, if any.
+func (rd *RingDeque[T]) evictValues(vs []T) {
+	if rd.onEvict == nil {
+		return
+	}
+	for _, v := range vs {
+		rd.onEvict(v)
+	}
+}
+
+// dropFront removes n values from the front of src and passes them to the OnEvict
+// callback of rd, if any.
+func (rd *RingDeque[T]) dropFront(src *RingDeque[T], n int) {
+	if rd.onEvict == nil {
+		src.DiscardFront(n)
+		return
+	}
+	for i := 0; i < n; i++ {
+		rd.onEvict(src.PopFront())
+	}
+}
+
+// dropBack removes n values from the back of src and passes them to the OnEvict
+// callback of rd, if any.
+func (rd *RingDeque[T]) dropBack(src *RingDeque[T], n int) {
+	if rd.onEvict == nil {
+		src.DiscardBack(n)
+		return
+	}
+	for i := 0; i < n; i++ {
+		rd.onEvict(src.PopBack())
+	}
+}
+
+// PushBack adds a new value at the back of rd.
+//
+// If rd is full, PushBack grows rd or follows the overflow policy of rd.
+func (rd *RingDeque[T]) PushBack(v T) {
+	if rd.count >= rd.limit && !rd.makeRoom(v, false) {
+		return
+	}
+	rd.buf[(rd.head+rd.count)&rd.mask] = v
+	rd.count++
+}
+
+// PushFront adds a new value at the front of rd.
+//
+// If rd is full, PushFront grows rd or follows the overflow policy of rd.
+func (rd *RingDeque[T]) PushFront(v T) {
+	if rd.count >= rd.limit && !rd.makeRoom(v, true) {
+		return
+	}
+	rd.head = (rd.head - 1) & rd.mask
+	rd.buf[rd.head] = v
+	rd.count++
+}
+
+// TryPushBack is similar to PushBack except that it returns whether v is added
+// to rd. It returns false only if rd is full and its overflow policy is DropNewest
+// or Reject.
+func (rd *RingDeque[T]) TryPushBack(v T) bool {
+	if rd.count >= rd.limit && !rd.makeRoom(v, false) {
+		return false
+	}
+	rd.PushBack(v)
+	return true
+}
+
+// TryPushFront is similar to PushFront except that it returns whether v is added
+// to rd. It returns false only if rd is full and its overflow policy is DropNewest
+// or Reject.
+func (rd *RingDeque[T]) TryPushFront(v T) bool {
+	if rd.count >= rd.limit && !rd.makeRoom(v, true) {
+		return false
+	}
+	rd.PushFront(v)
+	return true
+}
+
+// PushBackSlice adds all the values in vs at the back of rd, keeping their order.
+//
+// If vs does not fit, PushBackSlice grows rd or follows the overflow policy of rd.
+func (rd *RingDeque[T]) PushBackSlice(vs []T) {
+	rd.reserve(rd.count + len(vs))
+	if rd.count+len(vs) > rd.limit {
+		if rd.policy == Panic {
+			panic(errFull)
+		}
+		for _, v := range vs {
+			rd.PushBack(v)
+		}
+		return
+	}
+
+	s := (rd.head + rd.count) & rd.mask
+	if n := copy(rd.buf[s:], vs); n < len(vs) {
+		copy(rd.buf, vs[n:])
+	}
+	rd.count += len(vs)
+}
+
+// PushFrontSlice adds all the values in vs at the front of rd, keeping their order.
+// After the call, vs[0] is the first value of rd.
+//
+// If vs does not fit, PushFrontSlice grows rd or follows the overflow policy of rd.
+func (rd *RingDeque[T]) PushFrontSlice(vs []T) {
+	rd.reserve(rd.count + len(vs))
+	if rd.count+len(vs) > rd.limit {
+		if rd.policy == Panic {
+			panic(errFull)
+		}
+		for i := len(vs) - 1; i >= 0; i-- {
+			rd.PushFront(vs[i])
+		}
+		return
+	}
+
+	rd.head = (rd.head - len(vs)) & rd.mask
+	if n := copy(rd.buf[rd.head:], vs); n < len(vs) {
+		copy(rd.buf, vs[n:])
+	}
+	rd.count += len(vs)
+}
+
+// TryPopBack tries to remove a value from the back of rd and returns the removed value if any.
+// The return value ok indicates whether it succeeded.
+func (rd *RingDeque[T]) TryPopBack() (_ T, ok bool) {
+	if rd.count == 0 {
+		return *new(T), false
+	}
+	rd.count--
+	i := (rd.head + rd.count) & rd.mask
+	r := rd.buf[i]
+	var defVal T
+	rd.buf[i] = defVal
+	return r, true
+}
+
+// PopBack removes a value from the back of rd and returns the removed value.
+// It panics if rd is empty.
+func (rd *RingDeque[T]) PopBack() T {
+	if v, ok := rd.TryPopBack(); ok {
+		return v
+	}
+	panic(errEmpty)
+}
+
+// TryPopFront tries to remove a value from the front of rd and returns the removed value if any.
+// The return value ok indicates whether it succeeded.
+func (rd *RingDeque[T]) TryPopFront() (_ T, ok bool) {
+	if rd.count == 0 {
+		return *new(T), false
+	}
+	r := rd.buf[rd.head]
+	var defVal T
+	rd.buf[rd.head] = defVal
+	rd.head = (rd.head + 1) & rd.mask
+	rd.count--
+	return r, true
+}
+
+// PopFront removes a value from the front of rd and returns the removed value.
+// It panics if rd is empty.
+func (rd *RingDeque[T]) PopFront() T {
+	if v, ok := rd.TryPopFront(); ok {
+		return v
+	}
+	panic(errEmpty)
+}
+
+// TryPopFrontIf is similar to TryPopFront except that it removes the first value
+// of rd only if pred returns true for it.
+func (rd *RingDeque[T]) TryPopFrontIf(pred func(T) bool) (_ T, ok bool) {
+	if rd.count == 0 || !pred(rd.buf[rd.head]) {
+		return *new(T), false
+	}
+	return rd.TryPopFront()
+}
+
+// TryPopBackIf is similar to TryPopBack except that it removes the last value
+// of rd only if pred returns true for it.
+func (rd *RingDeque[T]) TryPopBackIf(pred func(T) bool) (_ T, ok bool) {
+	if rd.count == 0 || !pred(rd.buf[(rd.head+rd.count-1)&rd.mask]) {
+		return *new(T), false
+	}
+	return rd.TryPopBack()
+}
+
+// PopFrontWhile removes values from the front of rd as long as pred returns true
+// for them, and returns the number of the removed values.
+func (rd *RingDeque[T]) PopFrontWhile(pred func(T) bool) int {
+	var n int
+	for {
+		if _, ok := rd.TryPopFrontIf(pred); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// PopFrontWhileWithBuffer is similar to PopFrontWhile except that it returns
+// the removed values, or nil if no value is removed. It uses buf to store the
+// removed values as long as it has enough space.
+func (rd *RingDeque[T]) PopFrontWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	buf = buf[:0]
+	for {
+		v, ok := rd.TryPopFrontIf(pred)
+		if !ok {
+			break
+		}
+		buf = append(buf, v)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
+
+// PopBackWhile removes values from the back of rd as long as pred returns true
+// for them, and returns the number of the removed values.
+func (rd *RingDeque[T]) PopBackWhile(pred func(T) bool) int {
+	var n int
+	for {
+		if _, ok := rd.TryPopBackIf(pred); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// PopBackWhileWithBuffer is similar to PopBackWhile except that it returns
+// the removed values in the order they are removed, or nil if no value is
+// removed. It uses buf to store the removed values as long as it has enough space.
+func (rd *RingDeque[T]) PopBackWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	buf = buf[:0]
+	for {
+		v, ok := rd.TryPopBackIf(pred)
+		if !ok {
+			break
+		}
+		buf = append(buf, v)
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+	return buf
+}
+
+// DequeueMany removes a number of values from the front of rd and returns
+// the removed values or nil if rd is empty.
+//
+// If max <= 0, DequeueMany removes and returns all the values in rd.
+func (rd *RingDeque[T]) DequeueMany(max int) []T {
+	return rd.DequeueManyWithBuffer(max, nil)
+}
+
+// DequeueManyWithBuffer is similar to DequeueMany except that it uses
+// buf to store the removed values as long as it has enough space.
+func (rd *RingDeque[T]) DequeueManyWithBuffer(max int, buf []T) []T {
+	n := rd.count
+	if n == 0 {
+		return nil
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	if n <= cap(buf) {
+		buf = buf[:n]
+	} else {
+		buf = make([]T, n)
+	}
+
+	rd.copyTo(buf, 0, n)
+	rd.zero(0, n)
+	rd.head = (rd.head + n) & rd.mask
+	rd.count -= n
+	return buf
+}
+
+// PopBackMany removes a number of values from the back of rd and returns
+// the removed values or nil if rd is empty. The values are returned in
+// the order PopBack would remove them, i.e. the last value of rd comes first.
+//
+// If max <= 0, PopBackMany removes and returns all the values in rd.
+func (rd *RingDeque[T]) PopBackMany(max int) []T {
+	return rd.PopBackManyWithBuffer(max, nil)
+}
+
+// PopBackManyWithBuffer is similar to PopBackMany except that it uses
+// buf to store the removed values as long as it has enough space.
+func (rd *RingDeque[T]) PopBackManyWithBuffer(max int, buf []T) []T {
+	n := rd.count
+	if n == 0 {
+		return nil
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	if n <= cap(buf) {
+		buf = buf[:n]
+	} else {
+		buf = make([]T, n)
+	}
+
+	for i := range buf {
+		buf[i] = rd.buf[(rd.head+rd.count-1-i)&rd.mask]
+	}
+	rd.zero(rd.count-n, rd.count)
+	rd.count -= n
+	return buf
+}
+
+// DequeueManyFromBack is similar to PopBackMany except that the removed
+// values keep their original order, i.e. the last value of rd comes last.
+func (rd *RingDeque[T]) DequeueManyFromBack(max int) []T {
+	return rd.DequeueManyFromBackWithBuffer(max, nil)
+}
+
+// DequeueManyFromBackWithBuffer is similar to DequeueManyFromBack except that
+// it uses buf to store the removed values as long as it has enough space.
+func (rd *RingDeque[T]) DequeueManyFromBackWithBuffer(max int, buf []T) []T {
+	n := rd.count
+	if n == 0 {
+		return nil
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	if n <= cap(buf) {
+		buf = buf[:n]
+	} else {
+		buf = make([]T, n)
+	}
+
+	rd.copyTo(buf, rd.count-n, rd.count)
+	rd.zero(rd.count-n, rd.count)
+	rd.count -= n
+	return buf
+}
+
+// DiscardFront removes at most n values from the front of rd and returns
+// the number of the removed values.
+func (rd *RingDeque[T]) DiscardFront(n int) int {
+	n = minInt(n, rd.count)
+	if n <= 0 {
+		return 0
+	}
+	rd.zero(0, n)
+	rd.head = (rd.head + n) & rd.mask
+	rd.count -= n
+	return n
+}
+
+// DiscardBack removes at most n values from the back of rd and returns
+// the number of the removed values.
+func (rd *RingDeque[T]) DiscardBack(n int) int {
+	n = minInt(n, rd.count)
+	if n <= 0 {
+		return 0
+	}
+	rd.zero(rd.count-n, rd.count)
+	rd.count -= n
+	return n
+}
+
+// Rotate moves n values from the front of rd to the back if n > 0, or -n values
+// from the back of rd to the front if n < 0. If rd is full, Rotate only moves the
+// head of the ring buffer.
+func (rd *RingDeque[T]) Rotate(n int) {
+	if rd.count <= 1 {
+		return
+	}
+	n %= rd.count
+	if n < 0 {
+		n += rd.count
+	}
+	if rd.count == len(rd.buf) {
+		rd.head = (rd.head + n) & rd.mask
+		return
+	}
+
+	var defVal T
+	if n <= rd.count/2 {
+		for i := 0; i < n; i++ {
+			rd.buf[(rd.head+rd.count)&rd.mask] = rd.buf[rd.head]
+			rd.buf[rd.head] = defVal
+			rd.head = (rd.head + 1) & rd.mask
+		}
+	} else {
+		for i := n; i < rd.count; i++ {
+			last := (rd.head + rd.count - 1) & rd.mask
+			rd.head = (rd.head - 1) & rd.mask
+			rd.buf[rd.head] = rd.buf[last]
+			rd.buf[last] = defVal
+		}
+	}
+}
+
+// Back returns the last value of rd if any. The return value ok
+// indicates whether it succeeded.
+func (rd *RingDeque[T]) Back() (_ T, ok bool) {
+	if rd.count == 0 {
+		return *new(T), false
+	}
+	return rd.buf[(rd.head+rd.count-1)&rd.mask], true
+}
+
+// Front returns the first value of rd if any. The return value ok
+// indicates whether it succeeded.
+func (rd *RingDeque[T]) Front() (_ T, ok bool) {
+	if rd.count == 0 {
+		return *new(T), false
+	}
+	return rd.buf[rd.head], true
+}
+
+// IsEmpty returns whether rd is empty.
+func (rd *RingDeque[T]) IsEmpty() bool {
+	return rd.count == 0
+}
+
+// Len returns the number of values in rd.
+func (rd *RingDeque[T]) Len() int {
+	return rd.count
+}
+
+// Cap returns the current capacity of rd.
+func (rd *RingDeque[T]) Cap() int {
+	return len(rd.buf)
+}
+
+// MaxLen returns the max length of rd, or 0 if rd has no limit.
+func (rd *RingDeque[T]) MaxLen() int {
+	if rd.maxLen == maxLenUnlimited {
+		return 0
+	}
+	return rd.maxLen
+}
+
+// Enqueue is an alias of PushBack.
+func (rd *RingDeque[T]) Enqueue(v T) {
+	rd.PushBack(v)
+}
+
+// TryDequeue is an alias of TryPopFront.
+func (rd *RingDeque[T]) TryDequeue() (_ T, ok bool) {
+	return rd.TryPopFront()
+}
+
+// Dequeue is an alias of PopFront.
+func (rd *RingDeque[T]) Dequeue() T {
+	return rd.PopFront()
+}
+
+// Dump returns all the values in rd.
+func (rd *RingDeque[T]) Dump() []T {
+	if rd.count == 0 {
+		return nil
+	}
+	vals := make([]T, rd.count)
+	rd.copyTo(vals, 0, rd.count)
+	return vals
+}
+
+// Range iterates all the values in rd. Do NOT add values to rd or remove values from rd during Range.
+func (rd *RingDeque[T]) Range(f func(i int, v T) bool) {
+	for i := 0; i < rd.count; i++ {
+		if !f(i, rd.buf[(rd.head+i)&rd.mask]) {
+			return
+		}
+	}
+}
+
+// Peek returns the value at idx. It panics if idx is out of range.
+func (rd *RingDeque[T]) Peek(idx int) T {
+	if idx < 0 || idx >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+	return rd.buf[(rd.head+idx)&rd.mask]
+}
+
+// Replace replaces the value at idx with v. It panics if idx is out of range.
+func (rd *RingDeque[T]) Replace(idx int, v T) {
+	if idx < 0 || idx >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+	rd.buf[(rd.head+idx)&rd.mask] = v
+}
+
+// At returns a pointer to the value at idx. It panics if idx is out of range.
+//
+// The pointer becomes invalid once rd grows, or the value is moved or removed.
+func (rd *RingDeque[T]) At(idx int) *T {
+	if idx < 0 || idx >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+	return &rd.buf[(rd.head+idx)&rd.mask]
+}
+
+// FrontPtr returns a pointer to the first value of rd, or nil if rd is empty.
+// See At for how long the pointer stays valid.
+func (rd *RingDeque[T]) FrontPtr() *T {
+	if rd.count == 0 {
+		return nil
+	}
+	return &rd.buf[rd.head]
+}
+
+// BackPtr returns a pointer to the last value of rd, or nil if rd is empty.
+// See At for how long the pointer stays valid.
+func (rd *RingDeque[T]) BackPtr() *T {
+	if rd.count == 0 {
+		return nil
+	}
+	return &rd.buf[(rd.head+rd.count-1)&rd.mask]
+}
+
+// Segments returns the values in [from, to) as a list of slices sharing the
+// memory of rd. Because the values wrap around the end of the ring buffer at
+// most once, there are at most two slices. It returns nil if the range is empty,
+// and it panics if from or to is out of range, or if from > to.
+//
+// The slices are only valid until the next modification of rd. Writing to them
+// modifies the values in rd.
+func (rd *RingDeque[T]) Segments(from, to int) [][]T {
+	return rd.SegmentsWithBuffer(from, to, nil)
+}
+
+// SegmentsWithBuffer is similar to Segments except that it uses buf to
+// store the slices as long as it has enough space.
+func (rd *RingDeque[T]) SegmentsWithBuffer(from, to int, buf [][]T) [][]T {
+	if from < 0 || from > rd.count {
+		panic(fmt.Errorf("out of range: %d", from))
+	}
+	if to < from || to > rd.count {
+		panic(fmt.Errorf("out of range: %d", to))
+	}
+	if from == to {
+		return nil
+	}
+
+	s := (rd.head + from) & rd.mask
+	e := s + to - from
+	if n := len(rd.buf); e > n {
+		return append(buf[:0], rd.buf[s:n:n], rd.buf[:e-n:e-n])
+	}
+	return append(buf[:0], rd.buf[s:e:e])
+}
+
+// Swap exchanges the two values at idx1 and idx2. It panics if idx1 or idx2 is out of range.
+func (rd *RingDeque[T]) Swap(idx1, idx2 int) {
+	if idx1 < 0 || idx1 >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx1))
+	}
+	if idx2 < 0 || idx2 >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx2))
+	}
+	i1 := (rd.head + idx1) & rd.mask
+	i2 := (rd.head + idx2) & rd.mask
+	rd.buf[i1], rd.buf[i2] = rd.buf[i2], rd.buf[i1]
+}
+
+// Insert inserts a new value v before the value at idx. It moves the values on
+// the shorter side of idx by one slot.
+//
+// If rd is full, Insert grows rd or follows the overflow policy of rd. Insert
+// works like PushFront if idx <= 0, and like PushBack if idx >= rd.Len().
+func (rd *RingDeque[T]) Insert(idx int, v T) {
+	if idx <= 0 {
+		rd.PushFront(v)
+		return
+	}
+	if idx >= rd.count {
+		rd.PushBack(v)
+		return
+	}
+	if rd.count >= rd.limit {
+		n := rd.count
+		if !rd.makeRoom(v, false) {
+			return
+		}
+		if rd.count < n {
+			idx--
+		}
+	}
+
+	if idx < rd.count/2 {
+		rd.head = (rd.head - 1) & rd.mask
+		for i := 0; i < idx; i++ {
+			rd.buf[(rd.head+i)&rd.mask] = rd.buf[(rd.head+i+1)&rd.mask]
+		}
+	} else {
+		for i := rd.count; i > idx; i-- {
+			rd.buf[(rd.head+i)&rd.mask] = rd.buf[(rd.head+i-1)&rd.mask]
+		}
+	}
+	rd.buf[(rd.head+idx)&rd.mask] = v
+	rd.count++
+}
+
+// InsertSlice inserts all the values in vs before the value at idx, keeping their
+// order. It moves the values on the shorter side of idx by len(vs) slots.
+//
+// If vs does not fit, InsertSlice grows rd or follows the overflow policy of rd.
+// InsertSlice works like PushFrontSlice if idx <= 0, and like PushBackSlice if
+// idx >= rd.Len().
+func (rd *RingDeque[T]) InsertSlice(idx int, vs []T) {
+	if len(vs) == 0 {
+		return
+	}
+	if idx <= 0 {
+		rd.PushFrontSlice(vs)
+		return
+	}
+	if idx >= rd.count {
+		rd.PushBackSlice(vs)
+		return
+	}
+	rd.reserve(rd.count + len(vs))
+	if excess := rd.count + len(vs) - rd.limit; excess > 0 {
+		vs, idx = rd.admitAt(vs, idx, excess)
+	}
+
+	n := len(vs)
+	if idx < rd.count-idx {
+		rd.head = (rd.head - n) & rd.mask
+		for i := 0; i < idx; i++ {
+			rd.buf[(rd.head+i)&rd.mask] = rd.buf[(rd.head+i+n)&rd.mask]
+		}
+	} else {
+		for i := rd.count - 1; i >= idx; i-- {
+			rd.buf[(rd.head+i+n)&rd.mask] = rd.buf[(rd.head+i)&rd.mask]
+		}
+	}
+	for i, v := range vs {
+		rd.buf[(rd.head+idx+i)&rd.mask] = v
+	}
+	rd.count += n
+}
+
+// admitAt follows the overflow policy of rd when vs is about to be inserted at
+// idx and excess values do not fit. It returns the values that should still be
+// inserted and where they should be inserted. With DropOldest, the values are
+// evicted in their order as if vs was already inserted.
+func (rd *RingDeque[T]) admitAt(vs []T, idx, excess int) ([]T, int) {
+	switch rd.policy {
+	case DropOldest:
+		n := minInt(excess, idx)
+		rd.evictFront(n)
+		rd.evictValues(vs[:excess-n])
+		return vs[excess-n:], idx - n
+	case DropNewest:
+		rd.evictValues(vs[len(vs)-excess:])
+		return vs[:len(vs)-excess], idx
+	case Reject:
+		return vs[:len(vs)-excess], idx
+	default:
+		panic(errFull)
+	}
+}
+
+// Remove removes the value at idx. It moves the values on the shorter side of
+// idx by one slot. It panics if idx is out of range.
+func (rd *RingDeque[T]) Remove(idx int) {
+	if idx < 0 || idx >= rd.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+
+	var defVal T
+	if idx < rd.count/2 {
+		for i := idx; i > 0; i-- {
+			rd.buf[(rd.head+i)&rd.mask] = rd.buf[(rd.head+i-1)&rd.mask]
+		}
+		rd.buf[rd.head] = defVal
+		rd.head = (rd.head + 1) & rd.mask
+	} else {
+		for i := idx; i < rd.count-1; i++ {
+			rd.buf[(rd.head+i)&rd.mask] = rd.buf[(rd.head+i+1)&rd.mask]
+		}
+		rd.buf[(rd.head+rd.count-1)&rd.mask] = defVal
+	}
+	rd.count--
+}
+
+// RemoveRange removes the values in [from, to). It moves the values on the shorter
+// side of the range. It panics if from or to is out of range, or if from > to.
+func (rd *RingDeque[T]) RemoveRange(from, to int) {
+	if from < 0 || from > rd.count {
+		panic(fmt.Errorf("out of range: %d", from))
+	}
+	if to < from || to > rd.count {
+		panic(fmt.Errorf("out of range: %d", to))
+	}
+
+	n := to - from
+	if from < rd.count-to {
+		for i := from - 1; i >= 0; i-- {
+			rd.buf[(rd.head+i+n)&rd.mask] = rd.buf[(rd.head+i)&rd.mask]
+		}
+		rd.zero(0, n)
+		rd.head = (rd.head + n) & rd.mask
+	} else {
+		for i := to; i < rd.count; i++ {
+			rd.buf[(rd.head+i-n)&rd.mask] = rd.buf[(rd.head+i)&rd.mask]
+		}
+		rd.zero(rd.count-n, rd.count)
+	}
+	rd.count -= n
+}
+
+// RemoveFunc removes all the values satisfying pred from rd and returns the number
+// of the removed values. The remaining values are compacted in a single pass.
+func (rd *RingDeque[T]) RemoveFunc(pred func(T) bool) int {
+	return rd.filter(func(v T) bool {
+		return !pred(v)
+	}, nil)
+}
+
+// Retain removes all the values not satisfying pred from rd and returns the number
+// of the removed values. See RemoveFunc for details.
+func (rd *RingDeque[T]) Retain(pred func(T) bool) int {
+	return rd.filter(pred, nil)
+}
+
+// filter moves the values for which keep returns true toward the front of rd,
+// keeping their order. The other values are passed to drop if it is not nil, and
+// removed from rd. filter returns the number of the removed values.
+func (rd *RingDeque[T]) filter(keep func(T) bool, drop func(T)) int {
+	var w int
+	for i := 0; i < rd.count; i++ {
+		v := rd.buf[(rd.head+i)&rd.mask]
+		if !keep(v) {
+			if drop != nil {
+				drop(v)
+			}
+			continue
+		}
+		rd.buf[(rd.head+w)&rd.mask] = v
+		w++
+	}
+
+	removed := rd.count - w
+	rd.zero(w, rd.count)
+	rd.count = w
+	return removed
+}
+
+// Partition moves the values for which pred returns true to the front of rd,
+// and the others to the back. The original order of the values in each group
+// is kept. Partition returns the number of the values for which pred returns
+// true, which is also the index of the first value of the second group.
+func (rd *RingDeque[T]) Partition(pred func(T) bool) int {
+	var rest []T
+	rd.filter(pred, func(v T) {
+		rest = append(rest, v)
+	})
+
+	n := rd.count
+	for _, v := range rest {
+		rd.buf[(rd.head+rd.count)&rd.mask] = v
+		rd.count++
+	}
+	return n
+}
+
+// Reverse reverses the order of the values in rd in place.
+func (rd *RingDeque[T]) Reverse() {
+	for i, j := 0, rd.count-1; i < j; i, j = i+1, j-1 {
+		p, q := (rd.head+i)&rd.mask, (rd.head+j)&rd.mask
+		rd.buf[p], rd.buf[q] = rd.buf[q], rd.buf[p]
+	}
+}
+
+// Clone returns a copy of rd with the same capacity and options.
+func (rd *RingDeque[T]) Clone() *RingDeque[T] {
+	return rd.CloneFunc(nil)
+}
+
+// CloneFunc is similar to Clone except that every value is copied by calling
+// copyElem, which is useful when the values need to be deep copied. If copyElem
+// is nil, the values are copied by assignment.
+func (rd *RingDeque[T]) CloneFunc(copyElem func(T) T) *RingDeque[T] {
+	nr := *rd
+	nr.buf = make([]T, len(rd.buf))
+	if copyElem == nil {
+		copy(nr.buf, rd.buf)
+	} else {
+		for i := 0; i < rd.count; i++ {
+			j := (rd.head + i) & rd.mask
+			nr.buf[j] = copyElem(rd.buf[j])
+		}
+	}
+	return &nr
+}
+
+// Clear removes all the values from rd. The capacity of rd stays the same.
+func (rd *RingDeque[T]) Clear() {
+	rd.zero(0, rd.count)
+	rd.head = 0
+	rd.count = 0
+}
+
+// IndexFunc returns the index of the first value satisfying f, or -1 if none do.
+func (rd *RingDeque[T]) IndexFunc(f func(T) bool) int {
+	for i := 0; i < rd.count; i++ {
+		if f(rd.buf[(rd.head+i)&rd.mask]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// LastIndexFunc returns the index of the last value satisfying f, or -1 if none do.
+func (rd *RingDeque[T]) LastIndexFunc(f func(T) bool) int {
+	for i := rd.count - 1; i >= 0; i-- {
+		if f(rd.buf[(rd.head+i)&rd.mask]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ContainsFunc returns whether at least one value in rd satisfies f.
+func (rd *RingDeque[T]) ContainsFunc(f func(T) bool) bool {
+	return rd.IndexFunc(f) >= 0
+}
+
+// CountFunc returns the number of the values in rd satisfying f.
+func (rd *RingDeque[T]) CountFunc(f func(T) bool) int {
+	var n int
+	for i := 0; i < rd.count; i++ {
+		if f(rd.buf[(rd.head+i)&rd.mask]) {
+			n++
+		}
+	}
+	return n
+}
+
+// EqualFunc returns whether rd and other have the same length and eq returns
+// true for each pair of values at the same index.
+func (rd *RingDeque[T]) EqualFunc(other *RingDeque[T], eq func(a, b T) bool) bool {
+	if rd.count != other.count {
+		return false
+	}
+	for i := 0; i < rd.count; i++ {
+		if !eq(rd.buf[(rd.head+i)&rd.mask], other.buf[(other.head+i)&other.mask]) {
+			return false
+		}
+	}
+	return true
+}
+
+type ringSorter[T any] struct {
+	rd  *RingDeque[T]
+	cmp func(a, b T) int
+}
+
+func (x *ringSorter[T]) at(i int) *T {
+	return &x.rd.buf[(x.rd.head+i)&x.rd.mask]
+}
+
+func (x *ringSorter[T]) Len() int {
+	return x.rd.count
+}
+
+func (x *ringSorter[T]) Less(i, j int) bool {
+	return x.cmp(*x.at(i), *x.at(j)) < 0
+}
+
+func (x *ringSorter[T]) Swap(i, j int) {
+	p1, p2 := x.at(i), x.at(j)
+	*p1, *p2 = *p2, *p1
+}
+
+// SortFunc sorts the values in rd in ascending order as determined by cmp,
+// which should return a negative number when a < b, a positive number when
+// a > b and zero when a == b. SortFunc is not guaranteed to be stable.
+func (rd *RingDeque[T]) SortFunc(cmp func(a, b T) int) {
+	if rd.count < 2 {
+		return
+	}
+	sort.Sort(&ringSorter[T]{rd: rd, cmp: cmp})
+}
+
+// SortStableFunc is similar to SortFunc except that it keeps the original
+// order of equal values.
+func (rd *RingDeque[T]) SortStableFunc(cmp func(a, b T) int) {
+	if rd.count < 2 {
+		return
+	}
+	sort.Stable(&ringSorter[T]{rd: rd, cmp: cmp})
+}
+
+// BinarySearchFunc searches for target in rd, which must be sorted in ascending
+// order as determined by cmp. It returns the position where target is found, or
+// the position where target would appear in the sort order, and a bool saying
+// whether target is really found.
+//
+// cmp should return a negative number if a < b, a positive number if a > b and
+// zero if a == b.
+func (rd *RingDeque[T]) BinarySearchFunc(target T, cmp func(a, b T) int) (int, bool) {
+	i := sort.Search(rd.count, func(i int) bool {
+		return cmp(rd.buf[(rd.head+i)&rd.mask], target) >= 0
+	})
+	return i, i < rd.count && cmp(rd.buf[(rd.head+i)&rd.mask], target) == 0
+}
+
+// AppendDeque moves all the values of src to the back of rd, keeping their order.
+// src becomes empty after the call.
+//
+// If the values do not fit, AppendDeque grows rd or follows the overflow policy
+// of rd. With Reject, the values that do not fit stay in src.
+func (rd *RingDeque[T]) AppendDeque(src *RingDeque[T]) {
+	if src == rd {
+		return
+	}
+	src.TransferFrontTo(rd, src.count)
+}
+
+// PrependDeque moves all the values of src to the front of rd, keeping their order.
+// src becomes empty after the call.
+//
+// If the values do not fit, PrependDeque grows rd or follows the overflow policy
+// of rd like PushFrontSlice does. With Reject, the values that do not fit stay
+// in src.
+func (rd *RingDeque[T]) PrependDeque(src *RingDeque[T]) {
+	if src == rd || src.count == 0 {
+		return
+	}
+
+	rd.reserve(rd.count + src.count)
+	n, drop := src.count, 0
+	if room := rd.limit - rd.count; n > room {
+		switch rd.policy {
+		case DropNewest:
+			n, drop = room, n-room
+		case Reject:
+			n = room
+		case Panic:
+			panic(errFull)
+		}
+	}
+
+	var a [2][]T
+	segs := src.SegmentsWithBuffer(src.count-n, src.count, a[:0])
+	for i := len(segs) - 1; i >= 0; i-- {
+		rd.PushFrontSlice(segs[i])
+	}
+	src.DiscardBack(n)
+	rd.dropBack(src, drop)
+}
+
+// TransferFrontTo moves at most n values from the front of rd to the back of dst,
+// keeping their order, and returns the number of the moved values.
+//
+// If the values do not fit, TransferFrontTo grows dst or follows the overflow
+// policy of dst. With DropNewest, the values that do not fit are removed from rd
+// but are not counted as moved. With Reject, they stay in rd.
+func (rd *RingDeque[T]) TransferFrontTo(dst *RingDeque[T], n int) int {
+	n = minInt(n, rd.count)
+	if n <= 0 {
+		return 0
+	}
+	if dst == rd {
+		rd.Rotate(n)
+		return n
+	}
+
+	dst.reserve(dst.count + n)
+	drop := 0
+	if room := dst.limit - dst.count; n > room {
+		switch dst.policy {
+		case DropNewest:
+			n, drop = room, n-room
+		case Reject:
+			n = room
+		case Panic:
+			panic(errFull)
+		}
+	}
+
+	var a [2][]T
+	for _, seg := range rd.SegmentsWithBuffer(0, n, a[:0]) {
+		dst.PushBackSlice(seg)
+	}
+	rd.DiscardFront(n)
+	dst.dropFront(rd, drop)
+	return n
+}
+
+// SplitAt splits rd into two at idx. rd keeps the values in [0, idx), and the
+// values in [idx, rd.Len()) are moved to a new RingDeque with the same capacity
+// and options as rd, which is returned. It panics if idx is out of range.
+func (rd *RingDeque[T]) SplitAt(idx int) *RingDeque[T] {
+	if idx < 0 || idx > rd.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+
+	nr := &RingDeque[T]{
+		grow:    rd.grow,
+		maxLen:  rd.maxLen,
+		policy:  rd.policy,
+		onEvict: rd.onEvict,
+	}
+	nr.resize(len(rd.buf))
+	rd.copyTo(nr.buf, idx, rd.count)
+	nr.count = rd.count - idx
+	rd.zero(idx, rd.count)
+	rd.count = idx
+	return nr
+}
@@ -0,0 +1,416 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func ringInvariant(t *testing.T, rd *RingDeque[int]) {
+	t.Helper()
+	if n := len(rd.buf); n&(n-1) != 0 || rd.mask != n-1 {
+		t.Fatal(`the size of rd.buf should be a power of two`)
+	}
+	if rd.head < 0 || rd.head >= len(rd.buf) {
+		t.Fatal(`rd.head < 0 || rd.head >= len(rd.buf)`)
+	}
+	if rd.count < 0 || rd.count > rd.limit || rd.limit > len(rd.buf) {
+		t.Fatal(`rd.count < 0 || rd.count > rd.limit || rd.limit > len(rd.buf)`)
+	}
+	for i := rd.count; i < len(rd.buf); i++ {
+		if rd.buf[(rd.head+i)&rd.mask] != 0 {
+			t.Fatal(`any value beyond the live region should always be the default value of its type`)
+		}
+	}
+}
+
+func checkRing(t *testing.T, rd *RingDeque[int], dq *Deque[int], str string) {
+	t.Helper()
+	ringInvariant(t, rd)
+	if rd.Len() != dq.Len() || rd.IsEmpty() != dq.IsEmpty() {
+		t.Fatalf("rd.Len() != dq.Len(). %s", str)
+	}
+	checkBufs(nil, rd.Dump(), dq.Dump(), str, t)
+	v1, ok1 := rd.Front()
+	v2, ok2 := dq.Front()
+	if v1 != v2 || ok1 != ok2 {
+		t.Fatalf("rd.Front() != dq.Front(). %s", str)
+	}
+	v1, ok1 = rd.Back()
+	v2, ok2 = dq.Back()
+	if v1 != v2 || ok1 != ok2 {
+		t.Fatalf("rd.Back() != dq.Back(). %s", str)
+	}
+}
+
+//gocyclo:ignore
+func TestRingDeque_Random(t *testing.T) {
+	type config struct {
+		capacity int
+		opts     []Option
+	}
+	var configs []config
+	for _, policy := range []OverflowPolicy{DropOldest, DropNewest, Reject} {
+		for _, capacity := range []int{1, 5, 8, 16} {
+			configs = append(configs, config{capacity, []Option{WithOverflowPolicy(policy)}})
+			configs = append(configs, config{capacity, []Option{WithOverflowPolicy(policy), WithMaxLen(6)}})
+			configs = append(configs, config{capacity, []Option{WithOverflowPolicy(policy), WithMaxLen(40), WithGrowth()}})
+		}
+	}
+	configs = append(configs, config{4, []Option{WithGrowth()}})
+
+	r := rand.New(rand.NewSource(1))
+	for ci, cfg := range configs {
+		var evicted1, evicted2 []int
+		rd := NewRingDeque[int](cfg.capacity, append(cfg.opts, WithOnEvict(func(v int) {
+			evicted1 = append(evicted1, v)
+		}))...)
+		maxLen := rd.limit
+		if rd.grow {
+			maxLen = rd.MaxLen()
+		}
+		dq := NewDeque[int](append(cfg.opts, WithChunkSize(8), WithMaxLen(maxLen), WithOnEvict(func(v int) {
+			evicted2 = append(evicted2, v)
+		}))...)
+		rd2 := NewRingDeque[int](cfg.capacity, append(cfg.opts, WithOnEvict(func(v int) {
+			evicted1 = append(evicted1, v)
+		}))...)
+		dq2 := NewDeque[int](append(cfg.opts, WithChunkSize(8), WithMaxLen(maxLen), WithOnEvict(func(v int) {
+			evicted2 = append(evicted2, v)
+		}))...)
+
+		var next int
+		var buf1, buf2 []int
+		for i := 0; i < 5000; i++ {
+			op := r.Intn(32)
+			str := fmt.Sprintf("config: %d, i: %d, op: %d", ci, i, op)
+			switch op {
+			case 0:
+				next++
+				rd.PushBack(next)
+				dq.PushBack(next)
+			case 1:
+				next++
+				rd.PushFront(next)
+				dq.PushFront(next)
+			case 2:
+				next++
+				if rd.TryPushBack(next) != dq.TryPushBack(next) {
+					t.Fatalf("unexpected result of TryPushBack. %s", str)
+				}
+			case 3:
+				next++
+				if rd.TryPushFront(next) != dq.TryPushFront(next) {
+					t.Fatalf("unexpected result of TryPushFront. %s", str)
+				}
+			case 4:
+				vs := newValues(r, &next, 12)
+				rd.PushBackSlice(vs)
+				dq.PushBackSlice(vs)
+			case 5:
+				vs := newValues(r, &next, 12)
+				rd.PushFrontSlice(vs)
+				dq.PushFrontSlice(vs)
+			case 6:
+				v1, ok1 := rd.TryPopFront()
+				v2, ok2 := dq.TryPopFront()
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("unexpected result of TryPopFront. %s", str)
+				}
+			case 7:
+				v1, ok1 := rd.TryPopBack()
+				v2, ok2 := dq.TryPopBack()
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("unexpected result of TryPopBack. %s", str)
+				}
+			case 8:
+				pred := func(v int) bool { return v%2 == 0 }
+				v1, ok1 := rd.TryPopFrontIf(pred)
+				v2, ok2 := dq.TryPopFrontIf(pred)
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("unexpected result of TryPopFrontIf. %s", str)
+				}
+				v1, ok1 = rd.TryPopBackIf(pred)
+				v2, ok2 = dq.TryPopBackIf(pred)
+				if v1 != v2 || ok1 != ok2 {
+					t.Fatalf("unexpected result of TryPopBackIf. %s", str)
+				}
+			case 9:
+				max := r.Intn(8)
+				buf1 = rd.DequeueManyWithBuffer(max, buf1)
+				buf2 = dq.DequeueManyWithBuffer(max, buf2)
+				checkBufs(nil, buf1, buf2, str, t)
+			case 10:
+				max := r.Intn(8)
+				buf1 = rd.PopBackManyWithBuffer(max, buf1)
+				buf2 = dq.PopBackManyWithBuffer(max, buf2)
+				checkBufs(nil, buf1, buf2, str, t)
+			case 11:
+				n := r.Intn(5)
+				if rd.DiscardFront(n) != dq.DiscardFront(n) {
+					t.Fatalf("unexpected result of DiscardFront. %s", str)
+				}
+			case 12:
+				n := r.Intn(5)
+				if rd.DiscardBack(n) != dq.DiscardBack(n) {
+					t.Fatalf("unexpected result of DiscardBack. %s", str)
+				}
+			case 13:
+				n := r.Intn(41) - 20
+				rd.Rotate(n)
+				dq.Rotate(n)
+			case 14, 15:
+				next++
+				idx := r.Intn(dq.Len() + 1)
+				rd.Insert(idx, next)
+				dq.Insert(idx, next)
+			case 16:
+				if dq.Len() > 0 {
+					idx := r.Intn(dq.Len())
+					rd.Remove(idx)
+					dq.Remove(idx)
+				}
+			case 17:
+				if dq.Len() > 0 {
+					idx1, idx2 := r.Intn(dq.Len()), r.Intn(dq.Len())
+					next++
+					rd.Replace(idx1, next)
+					dq.Replace(idx1, next)
+					rd.Swap(idx1, idx2)
+					dq.Swap(idx1, idx2)
+					if rd.Peek(idx2) != next || *rd.At(idx2) != next {
+						t.Fatalf("rd.Peek(idx2) != next. %s", str)
+					}
+				}
+			case 18:
+				rd.Reverse()
+				dq.Reverse()
+			case 19:
+				if r.Intn(10) == 0 {
+					rd.Clear()
+					dq.Clear()
+				}
+			case 20:
+				idx := r.Intn(dq.Len() + 1)
+				vs := newValues(r, &next, 12)
+				rd.InsertSlice(idx, vs)
+				dq.InsertSlice(idx, vs)
+			case 21:
+				from := r.Intn(dq.Len() + 1)
+				to := from + r.Intn(dq.Len()-from+1)
+				rd.RemoveRange(from, to)
+				dq.RemoveRange(from, to)
+			case 22:
+				pred := func(v int) bool { return v%3 != 0 }
+				buf1 = rd.PopFrontWhileWithBuffer(pred, buf1)
+				buf2 = dq.PopFrontWhileWithBuffer(pred, buf2)
+				checkBufs(nil, buf1, buf2, str, t)
+				if rd.PopBackWhile(pred) != dq.PopBackWhile(pred) {
+					t.Fatalf("unexpected result of PopBackWhile. %s", str)
+				}
+			case 23:
+				max := r.Intn(8)
+				buf1 = rd.DequeueManyFromBackWithBuffer(max, buf1)
+				buf2 = dq.DequeueManyFromBackWithBuffer(max, buf2)
+				checkBufs(nil, buf1, buf2, str, t)
+			case 24:
+				if rd.RemoveFunc(func(v int) bool { return v%7 == 0 }) != dq.RemoveFunc(func(v int) bool { return v%7 == 0 }) {
+					t.Fatalf("unexpected result of RemoveFunc. %s", str)
+				}
+				if rd.Retain(func(v int) bool { return v%11 != 0 }) != dq.Retain(func(v int) bool { return v%11 != 0 }) {
+					t.Fatalf("unexpected result of Retain. %s", str)
+				}
+			case 25:
+				if rd.Partition(func(v int) bool { return v%2 == 0 }) != dq.Partition(func(v int) bool { return v%2 == 0 }) {
+					t.Fatalf("unexpected result of Partition. %s", str)
+				}
+			case 26:
+				cmp := func(a, b int) int { return a%4 - b%4 }
+				rd.SortStableFunc(cmp)
+				dq.SortStableFunc(cmp)
+			case 27:
+				cmp := func(a, b int) int { return a - b }
+				rd.SortFunc(cmp)
+				dq.SortFunc(cmp)
+				target := r.Intn(next + 2)
+				i1, ok1 := rd.BinarySearchFunc(target, cmp)
+				i2, ok2 := dq.BinarySearchFunc(target, cmp)
+				if i1 != i2 || ok1 != ok2 {
+					t.Fatalf("unexpected result of BinarySearchFunc. %s", str)
+				}
+			case 28:
+				f := func(v int) bool { return v%3 == 0 }
+				if rd.IndexFunc(f) != dq.IndexFunc(f) || rd.LastIndexFunc(f) != dq.LastIndexFunc(f) ||
+					rd.ContainsFunc(f) != dq.ContainsFunc(f) || rd.CountFunc(f) != dq.CountFunc(f) {
+					t.Fatalf("unexpected result of the queries. %s", str)
+				}
+				nr := rd.CloneFunc(func(v int) int { return v + 1 })
+				if !nr.EqualFunc(rd, func(a, b int) bool { return a == b+1 }) || nr.EqualFunc(rd2, func(a, b int) bool { return true }) != (rd.Len() == rd2.Len()) {
+					t.Fatalf("unexpected result of EqualFunc. %s", str)
+				}
+				from := r.Intn(dq.Len() + 1)
+				to := from + r.Intn(dq.Len()-from+1)
+				var vs []int
+				segs := rd.Segments(from, to)
+				for _, seg := range segs {
+					vs = append(vs, seg...)
+				}
+				if len(segs) > 2 {
+					t.Fatalf("len(segs) > 2. %s", str)
+				}
+				checkBufs(nil, vs, dq.Dump()[from:to], str, t)
+			case 29:
+				idx := r.Intn(dq.Len() + 1)
+				nr, nd := rd.SplitAt(idx), dq.SplitAt(idx)
+				checkRing(t, nr, nd, str)
+				rd.PrependDeque(nr)
+				dq.PrependDeque(nd)
+				checkRing(t, nr, nd, str)
+			case 30:
+				n := r.Intn(12)
+				if rd.TransferFrontTo(rd2, n) != dq.TransferFrontTo(dq2, n) {
+					t.Fatalf("unexpected result of TransferFrontTo. %s", str)
+				}
+			case 31:
+				if r.Intn(2) == 0 {
+					rd.AppendDeque(rd2)
+					dq.AppendDeque(dq2)
+				} else {
+					rd.PrependDeque(rd2)
+					dq.PrependDeque(dq2)
+				}
+			}
+
+			checkRing(t, rd, dq, str)
+			checkRing(t, rd2, dq2, str)
+			checkBufs(nil, evicted1, evicted2, str, t)
+		}
+	}
+}
+
+func TestRingDeque_Growth(t *testing.T) {
+	rd := NewRingDeque[int](3, WithGrowth())
+	if rd.Cap() != 4 || rd.MaxLen() != 0 {
+		t.Fatal(`rd.Cap() != 4 || rd.MaxLen() != 0`)
+	}
+	for i := 0; i < 5; i++ {
+		rd.PushBack(i)
+	}
+	if rd.Cap() != 8 {
+		t.Fatal(`rd.Cap() != 8`)
+	}
+	rd.PushFrontSlice(make([]int, 20))
+	if rd.Cap() != 32 || rd.Len() != 25 {
+		t.Fatal(`rd.Cap() != 32 || rd.Len() != 25`)
+	}
+	ringInvariant(t, rd)
+
+	rd = NewRingDeque[int](2, WithGrowth(), WithMaxLen(5), WithOverflowPolicy(Reject))
+	rd.PushBackSlice([]int{1, 2, 3, 4, 5, 6, 7})
+	if rd.Cap() != 8 || rd.Len() != 5 {
+		t.Fatal(`rd.Cap() != 8 || rd.Len() != 5`)
+	}
+	checkBufs(nil, rd.Dump(), []int{1, 2, 3, 4, 5}, "", t)
+
+	rd = NewRingDeque[int](4)
+	for i := 0; i < 10; i++ {
+		rd.PushBack(i)
+	}
+	if rd.Cap() != 4 {
+		t.Fatal(`rd.Cap() != 4`)
+	}
+	checkBufs(nil, rd.Dump(), []int{6, 7, 8, 9}, "", t)
+	rd.Rotate(1)
+	checkBufs(nil, rd.Dump(), []int{7, 8, 9, 6}, "", t)
+	if *rd.FrontPtr() != 7 || *rd.BackPtr() != 6 {
+		t.Fatal(`*rd.FrontPtr() != 7 || *rd.BackPtr() != 6`)
+	}
+
+	nr := rd.Clone()
+	nr.Replace(0, 100)
+	checkBufs(nil, rd.Dump(), []int{7, 8, 9, 6}, "", t)
+	checkBufs(nil, nr.Dump(), []int{100, 8, 9, 6}, "", t)
+}
+
+func TestRingDeque_Panic(t *testing.T) {
+	mustPanic := func(f func(rd *RingDeque[int])) {
+		t.Helper()
+		rd := NewRingDeque[int](2, WithOverflowPolicy(Panic))
+		rd.PushBack(1)
+		rd.PushBack(2)
+		defer func() {
+			t.Helper()
+			if recover() == nil {
+				t.Fatal(`f should panic`)
+			}
+			checkBufs(nil, rd.Dump(), []int{1, 2}, "", t)
+		}()
+		f(rd)
+	}
+
+	mustPanic(func(rd *RingDeque[int]) { rd.PushBack(3) })
+	mustPanic(func(rd *RingDeque[int]) { rd.PushFront(3) })
+	mustPanic(func(rd *RingDeque[int]) { rd.Insert(1, 3) })
+	mustPanic(func(rd *RingDeque[int]) { rd.PushBackSlice([]int{3}) })
+	mustPanic(func(rd *RingDeque[int]) { rd.PushFrontSlice([]int{3}) })
+	mustPanic(func(rd *RingDeque[int]) { rd.Peek(2) })
+	mustPanic(func(rd *RingDeque[int]) { rd.Remove(-1) })
+	mustPanic(func(rd *RingDeque[int]) { rd.InsertSlice(1, []int{3}) })
+	mustPanic(func(rd *RingDeque[int]) {
+		src := NewRingDeque[int](1)
+		src.PushBack(3)
+		rd.AppendDeque(src)
+	})
+	mustPanic(func(rd *RingDeque[int]) {
+		src := NewRingDeque[int](1)
+		src.PushBack(3)
+		rd.PrependDeque(src)
+	})
+	mustPanic(func(rd *RingDeque[int]) { rd.RemoveRange(1, 3) })
+	mustPanic(func(rd *RingDeque[int]) { rd.Segments(2, 1) })
+	mustPanic(func(rd *RingDeque[int]) { rd.SplitAt(3) })
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`PopFront should panic`)
+			}
+		}()
+		NewRingDeque[int](4).PopFront()
+	}()
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal(`NewRingDeque should panic`)
+			}
+			if err := r.(error).Error(); err != "the callback of WithOnEvict should be func(int), not func(string)" {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		}()
+		NewRingDeque[int](4, WithOnEvict(func(string) {}))
+	}()
+
+	rd := NewRingDeque[int](4)
+	rd.PushBackSlice([]int{1, 2, 3})
+	var n int
+	rd.Range(func(i int, v int) bool {
+		n++
+		return i < 1
+	})
+	if n != 2 {
+		t.Fatal(`n != 2`)
+	}
+	if rd.Dequeue() != 1 {
+		t.Fatal(`rd.Dequeue() != 1`)
+	}
+	rd.Enqueue(4)
+	if v, ok := rd.TryDequeue(); !ok || v != 2 {
+		t.Fatal(`!ok || v != 2`)
+	}
+	if rd.PopBack() != 4 || len(rd.DequeueMany(0)) != 1 || rd.PopBackMany(0) != nil {
+		t.Fatal(`unexpected result of PopBack, DequeueMany or PopBackMany`)
+	}
+	if rd.FrontPtr() != nil || rd.BackPtr() != nil || rd.DequeueManyFromBack(0) != nil {
+		t.Fatal(`unexpected result of FrontPtr, BackPtr or DequeueManyFromBack`)
+	}
+}
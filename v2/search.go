@@ -0,0 +1,54 @@
+package deque
+
+// search returns the smallest index i at which f(value at i) is true, assuming
+// that f is false for some (possibly empty) prefix of dq and true for the rest.
+// It also returns the position of the value, or len(dq.chunks) if i == dq.Len().
+//
+// search binary-searches the last values of the chunks first, and then the
+// values inside the chunk found.
+func (dq *Deque[T]) search(f func(T) bool) (i, j, k int) {
+	n := len(dq.chunks)
+	if dq.count == 0 {
+		return 0, n, 0
+	}
+
+	lo, hi := 0, n
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		c := dq.chunks[mid]
+		if !f(c.data[c.e-1]) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo == n {
+		return dq.count, n, 0
+	}
+
+	c := dq.chunks[lo]
+	s, e := c.s, c.e-1
+	for s < e {
+		mid := int(uint(s+e) >> 1)
+		if !f(c.data[mid]) {
+			s = mid + 1
+		} else {
+			e = mid
+		}
+	}
+	return dq.index(lo, s), lo, s
+}
+
+// BinarySearchFunc searches for target in dq, which must be sorted in ascending
+// order as determined by cmp. It returns the position where target is found, or
+// the position where target would appear in the sort order, and a bool saying
+// whether target is really found.
+//
+// cmp should return a negative number if a < b, a positive number if a > b and
+// zero if a == b.
+func (dq *Deque[T]) BinarySearchFunc(target T, cmp func(a, b T) int) (int, bool) {
+	i, j, k := dq.search(func(v T) bool {
+		return cmp(v, target) >= 0
+	})
+	return i, i < dq.count && cmp(dq.chunks[j].data[k], target) == 0
+}
@@ -0,0 +1,123 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestDeque_BinarySearchFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		dq.SortFunc(cmpInt)
+		sort.Ints(a)
+		for i := 0; i < total/2; i++ {
+			idx := r.Intn(len(a))
+			dq.Remove(idx)
+			a = append(a[:idx], a[idx+1:]...)
+		}
+
+		for target := -1; target <= total/2+1; target++ {
+			i, ok := dq.BinarySearchFunc(target, cmpInt)
+			j := sort.SearchInts(a, target)
+			if i != j || ok != (j < len(a) && a[j] == target) {
+				t.Fatalf("unexpected result: %d, %v. total: %d, target: %d", i, ok, total, target)
+			}
+		}
+	}
+}
+
+//gocyclo:ignore
+func TestSortedDeque(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	sd := NewSortedDeque[int](cmpInt, WithChunkSize(8))
+	if _, ok := sd.Floor(0); ok {
+		t.Fatal("ok should be false")
+	}
+	if _, ok := sd.Ceiling(0); ok {
+		t.Fatal("ok should be false")
+	}
+
+	var a []int
+	for i := 0; i < 2000; i++ {
+		v := r.Intn(500)
+		if r.Intn(4) == 0 {
+			ok := sd.RemoveValue(v)
+			j := sort.SearchInts(a, v)
+			if ok != (j < len(a) && a[j] == v) {
+				t.Fatalf("unexpected result of RemoveValue. i: %d", i)
+			}
+			if ok {
+				a = append(a[:j], a[j+1:]...)
+			}
+		} else {
+			sd.Add(v)
+			j := sort.SearchInts(a, v+1)
+			a = append(a[:j], append([]int{v}, a[j:]...)...)
+		}
+		invariant(t, sd.dq, skipChunkMerge())
+	}
+	checkBufs(nil, sd.Dump(), a, "", t)
+	if sd.Len() != len(a) {
+		t.Fatal(`sd.Len() != len(a)`)
+	}
+
+	for v := -1; v <= 501; v++ {
+		j := sort.SearchInts(a, v+1)
+		floor, ok := sd.Floor(v)
+		if ok != (j > 0) || ok && floor != a[j-1] {
+			t.Fatalf("unexpected result of Floor. v: %d", v)
+		}
+		j = sort.SearchInts(a, v)
+		ceiling, ok := sd.Ceiling(v)
+		if ok != (j < len(a)) || ok && ceiling != a[j] {
+			t.Fatalf("unexpected result of Ceiling. v: %d", v)
+		}
+		if idx, ok := sd.Search(v); idx != j || ok != sd.Contains(v) {
+			t.Fatalf("unexpected result of Search. v: %d", v)
+		}
+	}
+
+	for lo := -1; lo < 501; lo += 37 {
+		for hi := lo; hi < 520; hi += 53 {
+			var b []int
+			n := sort.SearchInts(a, lo)
+			sd.RangeBetween(lo, hi, func(i int, v int) bool {
+				if i != n+len(b) {
+					t.Fatalf("unexpected index: %d", i)
+				}
+				b = append(b, v)
+				return true
+			})
+			var expected []int
+			for _, v := range a {
+				if v >= lo && v < hi {
+					expected = append(expected, v)
+				}
+			}
+			checkBufs(nil, b, expected, fmt.Sprintf("lo: %d, hi: %d", lo, hi), t)
+		}
+	}
+
+	var n int
+	sd.RangeBetween(0, 1000, func(i int, v int) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Fatal(`n != 10`)
+	}
+
+	if v, ok := sd.TryPopFront(); !ok || v != a[0] {
+		t.Fatal(`v, ok := sd.TryPopFront(); !ok || v != a[0]`)
+	}
+	if v, ok := sd.TryPopBack(); !ok || v != a[len(a)-1] {
+		t.Fatal(`v, ok := sd.TryPopBack(); !ok || v != a[len(a)-1]`)
+	}
+	sd.Clear()
+	if !sd.IsEmpty() {
+		t.Fatal(`!sd.IsEmpty()`)
+	}
+}
@@ -0,0 +1,97 @@
+package deque
+
+import (
+	"sort"
+)
+
+type sorter[T any] struct {
+	chunks    []*chunk[T]
+	s         int
+	n         int
+	chunkSize int
+	cmp       func(a, b T) int
+}
+
+func (x *sorter[T]) at(i int) *T {
+	a := x.s + i
+	return &x.chunks[a/x.chunkSize].data[a%x.chunkSize]
+}
+
+func (x *sorter[T]) Len() int {
+	return x.n
+}
+
+func (x *sorter[T]) Less(i, j int) bool {
+	return x.cmp(*x.at(i), *x.at(j)) < 0
+}
+
+func (x *sorter[T]) Swap(i, j int) {
+	p1, p2 := x.at(i), x.at(j)
+	*p1, *p2 = *p2, *p1
+}
+
+// newSorter packs the values of dq so that the position of a value can be
+// calculated directly from its index.
+func (dq *Deque[T]) newSorter(cmp func(a, b T) int) *sorter[T] {
+	dq.filter(nil, nil)
+	x := &sorter[T]{
+		chunks:    dq.chunks,
+		n:         dq.count,
+		chunkSize: dq.chunkSize,
+		cmp:       cmp,
+	}
+	if len(dq.chunks) > 0 {
+		x.s = dq.chunks[0].s
+	}
+	return x
+}
+
+// SortFunc sorts the values in dq in ascending order as determined by cmp,
+// which should return a negative number when a < b, a positive number when
+// a > b and zero when a == b. SortFunc is not guaranteed to be stable.
+//
+// SortFunc packs the values first, after which all the chunks except the
+// first one and the last one are full, and then sorts them in place.
+func (dq *Deque[T]) SortFunc(cmp func(a, b T) int) {
+	if dq.count < 2 {
+		return
+	}
+	sort.Sort(dq.newSorter(cmp))
+}
+
+// SortStableFunc is similar to SortFunc except that it keeps the original
+// order of equal values.
+func (dq *Deque[T]) SortStableFunc(cmp func(a, b T) int) {
+	if dq.count < 2 {
+		return
+	}
+	sort.Stable(dq.newSorter(cmp))
+}
+
+// Partition moves the values for which pred returns true to the front of dq,
+// and the others to the back. The original order of the values in each group
+// is kept. Partition returns the number of the values for which pred returns
+// true, which is also the index of the first value of the second group.
+func (dq *Deque[T]) Partition(pred func(T) bool) int {
+	total := dq.count
+	var rest []*chunk[T]
+	var last *chunk[T]
+	dq.filter(pred, func(v T) {
+		if last == nil || last.e == dq.chunkSize {
+			last = dq.chunkPool.Get().(*chunk[T])
+			last.s, last.e = 0, 0
+			rest = append(rest, last)
+		}
+		last.data[last.e] = v
+		last.e++
+	})
+
+	n := dq.count
+	j := len(dq.chunks) - 1
+	for _, c := range rest {
+		dq.appendChunk(c)
+	}
+	dq.mergeChunks(j)
+	dq.count = total
+	return n
+}
@@ -0,0 +1,111 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func newRandomDeque(r *rand.Rand, total int) (*Deque[int], []int) {
+	dq := NewDeque[int](WithChunkSize(8))
+	var a []int
+	for i := 0; i < total; i++ {
+		v := r.Intn(total/2 + 1)
+		if i%2 == 0 {
+			dq.PushBack(v)
+			a = append(a, v)
+		} else {
+			dq.PushFront(v)
+			a = append([]int{v}, a...)
+		}
+	}
+	for i := 0; i < total/10; i++ {
+		idx := r.Intn(len(a))
+		dq.Remove(idx)
+		a = append(a[:idx], a[idx+1:]...)
+	}
+	return dq, a
+}
+
+func TestDeque_SortFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		dq.SortFunc(cmpInt)
+		sort.Ints(a)
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), a, fmt.Sprintf("total: %d", total), t)
+
+		dq.SortFunc(func(a, b int) int {
+			return cmpInt(b, a)
+		})
+		sort.Sort(sort.Reverse(sort.IntSlice(a)))
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), a, fmt.Sprintf("total: %d", total), t)
+	}
+}
+
+func TestDeque_SortStableFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		dq, a := newRandomDeque(r, total)
+		for i := range a {
+			a[i] = a[i]%10*10000 + i
+			dq.Replace(i, a[i])
+		}
+		byKey := func(a, b int) int {
+			return cmpInt(a/10000, b/10000)
+		}
+		dq.SortStableFunc(byKey)
+		sort.SliceStable(a, func(i, j int) bool {
+			return a[i]/10000 < a[j]/10000
+		})
+		invariant(t, dq)
+		checkBufs(nil, dq.Dump(), a, fmt.Sprintf("total: %d", total), t)
+	}
+}
+
+func TestDeque_Partition(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 2, 7, 8, 9, 100, 1000} {
+		for _, mod := range []int{1, 2, 3, 1000000} {
+			dq, a := newRandomDeque(r, total)
+			pred := func(v int) bool {
+				return v%mod == 0
+			}
+			n := dq.Partition(pred)
+
+			var expected []int
+			for _, v := range a {
+				if pred(v) {
+					expected = append(expected, v)
+				}
+			}
+			if n != len(expected) {
+				t.Fatalf("n != len(expected). total: %d, mod: %d", total, mod)
+			}
+			for _, v := range a {
+				if !pred(v) {
+					expected = append(expected, v)
+				}
+			}
+			invariant(t, dq)
+			checkBufs(nil, dq.Dump(), expected, fmt.Sprintf("total: %d, mod: %d", total, mod), t)
+			if dq.Len() != len(a) {
+				t.Fatal(`dq.Len() != len(a)`)
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package deque
+
+// SortedDeque is a Deque that keeps its values sorted in ascending order as
+// determined by a comparison function.
+type SortedDeque[T any] struct {
+	dq  *Deque[T]
+	cmp func(a, b T) int
+}
+
+// NewSortedDeque creates a new SortedDeque instance. cmp should return a negative
+// number if a < b, a positive number if a > b and zero if a == b.
+func NewSortedDeque[T any](cmp func(a, b T) int, opts ...Option) *SortedDeque[T] {
+	return &SortedDeque[T]{
+		dq:  NewDeque[T](opts...),
+		cmp: cmp,
+	}
+}
+
+// Add adds a new value v to sd. v is placed after the values equal to it.
+func (sd *SortedDeque[T]) Add(v T) {
+	i, _, _ := sd.dq.search(func(x T) bool {
+		return sd.cmp(x, v) > 0
+	})
+	sd.dq.Insert(i, v)
+}
+
+// Search returns the index of the first value equal to v, or the index where
+// v would be added if there is none, and a bool saying whether v is found.
+func (sd *SortedDeque[T]) Search(v T) (int, bool) {
+	return sd.dq.BinarySearchFunc(v, sd.cmp)
+}
+
+// Contains returns whether sd contains a value equal to v.
+func (sd *SortedDeque[T]) Contains(v T) bool {
+	_, ok := sd.dq.BinarySearchFunc(v, sd.cmp)
+	return ok
+}
+
+// RemoveValue removes the first value equal to v from sd. The return value
+// ok indicates whether such a value is found.
+func (sd *SortedDeque[T]) RemoveValue(v T) (ok bool) {
+	i, ok := sd.dq.BinarySearchFunc(v, sd.cmp)
+	if ok {
+		sd.dq.Remove(i)
+	}
+	return ok
+}
+
+// Floor returns the greatest value less than or equal to v if any. The return
+// value ok indicates whether such a value is found.
+func (sd *SortedDeque[T]) Floor(v T) (_ T, ok bool) {
+	i, _, _ := sd.dq.search(func(x T) bool {
+		return sd.cmp(x, v) > 0
+	})
+	if i == 0 {
+		return *new(T), false
+	}
+	return sd.dq.Peek(i - 1), true
+}
+
+// Ceiling returns the least value greater than or equal to v if any. The return
+// value ok indicates whether such a value is found.
+func (sd *SortedDeque[T]) Ceiling(v T) (_ T, ok bool) {
+	i, j, k := sd.dq.search(func(x T) bool {
+		return sd.cmp(x, v) >= 0
+	})
+	if i == sd.dq.count {
+		return *new(T), false
+	}
+	return sd.dq.chunks[j].data[k], true
+}
+
+// RangeBetween iterates the values in sd which are greater than or equal to lo
+// and less than hi, i.e. the values in [lo, hi). i is the index of a value in sd.
+// Do NOT add values to sd or remove values from sd during RangeBetween.
+func (sd *SortedDeque[T]) RangeBetween(lo, hi T, f func(i int, v T) bool) {
+	i, j, k := sd.dq.search(func(x T) bool {
+		return sd.cmp(x, lo) >= 0
+	})
+	for n := len(sd.dq.chunks); j < n; j++ {
+		c := sd.dq.chunks[j]
+		for ; k < c.e; k++ {
+			if sd.cmp(c.data[k], hi) >= 0 || !f(i, c.data[k]) {
+				return
+			}
+			i++
+		}
+		if j+1 < n {
+			k = sd.dq.chunks[j+1].s
+		}
+	}
+}
+
+// Peek returns the value at idx. It panics if idx is out of range.
+func (sd *SortedDeque[T]) Peek(idx int) T {
+	return sd.dq.Peek(idx)
+}
+
+// Remove removes the value at idx. It panics if idx is out of range.
+func (sd *SortedDeque[T]) Remove(idx int) {
+	sd.dq.Remove(idx)
+}
+
+// Front returns the least value of sd if any. The return value ok
+// indicates whether it succeeded.
+func (sd *SortedDeque[T]) Front() (_ T, ok bool) {
+	return sd.dq.Front()
+}
+
+// Back returns the greatest value of sd if any. The return value ok
+// indicates whether it succeeded.
+func (sd *SortedDeque[T]) Back() (_ T, ok bool) {
+	return sd.dq.Back()
+}
+
+// TryPopFront tries to remove the least value of sd and returns the removed value
+// if any. The return value ok indicates whether it succeeded.
+func (sd *SortedDeque[T]) TryPopFront() (_ T, ok bool) {
+	return sd.dq.TryPopFront()
+}
+
+// TryPopBack tries to remove the greatest value of sd and returns the removed value
+// if any. The return value ok indicates whether it succeeded.
+func (sd *SortedDeque[T]) TryPopBack() (_ T, ok bool) {
+	return sd.dq.TryPopBack()
+}
+
+// IsEmpty returns whether sd is empty.
+func (sd *SortedDeque[T]) IsEmpty() bool {
+	return sd.dq.IsEmpty()
+}
+
+// Len returns the number of values in sd.
+func (sd *SortedDeque[T]) Len() int {
+	return sd.dq.Len()
+}
+
+// Range iterates all the values in sd. Do NOT add values to sd or remove values
+// from sd during Range.
+func (sd *SortedDeque[T]) Range(f func(i int, v T) bool) {
+	sd.dq.Range(f)
+}
+
+// Dump returns all the values in sd.
+func (sd *SortedDeque[T]) Dump() []T {
+	return sd.dq.Dump()
+}
+
+// Clear removes all the values from sd.
+func (sd *SortedDeque[T]) Clear() {
+	sd.dq.Clear()
+}
@@ -0,0 +1,618 @@
+package deque
+
+import (
+	"sync"
+)
+
+// SyncDeque is a goroutine-safe wrapper of Deque. All its methods lock an internal
+// lock, so each of them is atomic. Use Do for a transaction of several operations.
+//
+// SyncDeque does not provide the methods of Deque that expose the memory of the
+// Deque, such as At, FrontPtr, BackPtr, Segments, ReserveBack, CursorAt, Reversed
+// and the iterators, because it cannot protect the memory after they return. They
+// can be used inside Do.
+//
+// The callbacks set by WithOnEvict and WithWatermarks are collected while the lock
+// is held and called after it is released, in the order they are triggered, so
+// they may call the methods of the SyncDeque. By the time a callback runs, other
+// goroutines may have modified the SyncDeque again.
+type SyncDeque[T any] struct {
+	mu        sync.RWMutex
+	dq        *Deque[T]
+	callbacks callbackQueue[T]
+}
+
+// NewSyncDeque creates a new SyncDeque instance.
+func NewSyncDeque[T any](opts ...Option) *SyncDeque[T] {
+	return newSyncDeque(NewDeque[T](opts...))
+}
+
+// NewSyncDequeFromSlice creates a new SyncDeque instance holding all the values in vs.
+func NewSyncDequeFromSlice[T any](vs []T, opts ...Option) *SyncDeque[T] {
+	return newSyncDeque(NewDequeFromSlice(vs, opts...))
+}
+
+func newSyncDeque[T any](dq *Deque[T]) *SyncDeque[T] {
+	sd := &SyncDeque[T]{dq: dq}
+	sd.callbacks.wrap(dq)
+	return sd
+}
+
+// callbackQueue defers the OnEvict and watermark callbacks of a Deque guarded by
+// a lock, so that they can be called after the lock is released.
+type callbackQueue[T any] struct {
+	onEvict func(T)
+	pending []func()
+}
+
+// wrap replaces the callbacks of dq with ones queuing the calls in q.
+func (q *callbackQueue[T]) wrap(dq *Deque[T]) {
+	if f := dq.onEvict; f != nil {
+		q.onEvict = f
+		dq.onEvict = func(v T) {
+			q.pending = append(q.pending, func() { f(v) })
+		}
+	}
+	if f := dq.onHigh; f != nil {
+		dq.onHigh = func() {
+			q.pending = append(q.pending, f)
+		}
+	}
+	if f := dq.onLow; f != nil {
+		dq.onLow = func() {
+			q.pending = append(q.pending, f)
+		}
+	}
+}
+
+// unwrap restores the original OnEvict callback of nd, which is split or cloned
+// from the Deque wrapped by q.
+func (q *callbackQueue[T]) unwrap(nd *Deque[T]) *Deque[T] {
+	nd.onEvict = q.onEvict
+	return nd
+}
+
+// take removes the queued calls from q and returns them. The lock guarding the
+// Deque must be held.
+func (q *callbackQueue[T]) take() []func() {
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
+
+// unlock releases the lock of sd, and then calls the callbacks triggered while
+// the lock was held.
+func (sd *SyncDeque[T]) unlock() {
+	pending := sd.callbacks.take()
+	sd.mu.Unlock()
+	for _, f := range pending {
+		f()
+	}
+}
+
+// Do calls f with the underlying Deque of sd while holding the lock of sd, so that
+// f can run several operations as a whole. Do NOT call the methods of sd in f, and
+// do NOT keep the Deque, or any pointer or slice obtained from it, after f returns.
+// The callbacks triggered in f are called after f returns.
+func (sd *SyncDeque[T]) Do(f func(dq *Deque[T])) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	f(sd.dq)
+}
+
+// PushBackIfLen adds v at the back of sd if the length of sd is less than n, and
+// returns whether v is added. If sd has a max length or a byte limit, v may still
+// be rejected or dropped according to the overflow policy of sd.
+func (sd *SyncDeque[T]) PushBackIfLen(v T, n int) bool {
+	sd.mu.Lock()
+	defer sd.unlock()
+	if sd.dq.count >= n {
+		return false
+	}
+	return sd.dq.TryPushBack(v)
+}
+
+// PopFrontIf removes the first value of sd and returns it if pred returns true for
+// it. The return value ok indicates whether a value is removed. It is the same as
+// TryPopFrontIf.
+func (sd *SyncDeque[T]) PopFrontIf(pred func(T) bool) (_ T, ok bool) {
+	return sd.TryPopFrontIf(pred)
+}
+
+// DrainTo removes all the values from sd, appends them to buf and returns the
+// extended buffer.
+func (sd *SyncDeque[T]) DrainTo(buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	if sd.dq.count == 0 {
+		return buf
+	}
+	for _, seg := range sd.dq.Segments(0, sd.dq.count) {
+		buf = append(buf, seg...)
+	}
+	sd.dq.Clear()
+	return buf
+}
+
+// Snapshot returns a copy of all the values in sd.
+func (sd *SyncDeque[T]) Snapshot() []T {
+	return sd.Dump()
+}
+
+// PushBack adds a new value at the back of sd.
+func (sd *SyncDeque[T]) PushBack(v T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.PushBack(v)
+}
+
+// PushFront adds a new value at the front of sd.
+func (sd *SyncDeque[T]) PushFront(v T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.PushFront(v)
+}
+
+// TryPushBack is similar to PushBack except that it returns whether v is added
+// to sd. See Deque.TryPushBack for details.
+func (sd *SyncDeque[T]) TryPushBack(v T) bool {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPushBack(v)
+}
+
+// TryPushFront is similar to PushFront except that it returns whether v is added
+// to sd. See Deque.TryPushFront for details.
+func (sd *SyncDeque[T]) TryPushFront(v T) bool {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPushFront(v)
+}
+
+// PushBackSlice adds all the values in vs at the back of sd, keeping their order.
+func (sd *SyncDeque[T]) PushBackSlice(vs []T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.PushBackSlice(vs)
+}
+
+// PushFrontSlice adds all the values in vs at the front of sd, keeping their order.
+func (sd *SyncDeque[T]) PushFrontSlice(vs []T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.PushFrontSlice(vs)
+}
+
+// TryPopBack tries to remove a value from the back of sd and returns the removed value
+// if any. The return value ok indicates whether it succeeded.
+func (sd *SyncDeque[T]) TryPopBack() (_ T, ok bool) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPopBack()
+}
+
+// PopBack removes a value from the back of sd and returns the removed value.
+// It panics if sd is empty.
+func (sd *SyncDeque[T]) PopBack() T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopBack()
+}
+
+// TryPopFront tries to remove a value from the front of sd and returns the removed value
+// if any. The return value ok indicates whether it succeeded.
+func (sd *SyncDeque[T]) TryPopFront() (_ T, ok bool) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPopFront()
+}
+
+// PopFront removes a value from the front of sd and returns the removed value.
+// It panics if sd is empty.
+func (sd *SyncDeque[T]) PopFront() T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopFront()
+}
+
+// TryPopFrontIf removes the first value of sd and returns it if pred returns true
+// for it. The return value ok indicates whether a value is removed.
+func (sd *SyncDeque[T]) TryPopFrontIf(pred func(T) bool) (_ T, ok bool) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPopFrontIf(pred)
+}
+
+// TryPopBackIf removes the last value of sd and returns it if pred returns true
+// for it. The return value ok indicates whether a value is removed.
+func (sd *SyncDeque[T]) TryPopBackIf(pred func(T) bool) (_ T, ok bool) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryPopBackIf(pred)
+}
+
+// PopFrontWhile removes values from the front of sd as long as pred returns true
+// for them, and returns the number of the removed values.
+func (sd *SyncDeque[T]) PopFrontWhile(pred func(T) bool) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopFrontWhile(pred)
+}
+
+// PopFrontWhileWithBuffer is similar to PopFrontWhile except that it returns
+// the removed values. See Deque.PopFrontWhileWithBuffer for details.
+func (sd *SyncDeque[T]) PopFrontWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopFrontWhileWithBuffer(pred, buf)
+}
+
+// PopBackWhile removes values from the back of sd as long as pred returns true
+// for them, and returns the number of the removed values.
+func (sd *SyncDeque[T]) PopBackWhile(pred func(T) bool) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopBackWhile(pred)
+}
+
+// PopBackWhileWithBuffer is similar to PopBackWhile except that it returns
+// the removed values. See Deque.PopBackWhileWithBuffer for details.
+func (sd *SyncDeque[T]) PopBackWhileWithBuffer(pred func(T) bool, buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopBackWhileWithBuffer(pred, buf)
+}
+
+// DequeueMany removes a number of values from the front of sd and returns
+// the removed values or nil if sd is empty. If max <= 0, DequeueMany removes
+// and returns all the values in sd.
+func (sd *SyncDeque[T]) DequeueMany(max int) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DequeueMany(max)
+}
+
+// DequeueManyWithBuffer is similar to DequeueMany except that it uses
+// buf to store the removed values as long as it has enough space.
+func (sd *SyncDeque[T]) DequeueManyWithBuffer(max int, buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DequeueManyWithBuffer(max, buf)
+}
+
+// PopBackMany removes a number of values from the back of sd and returns
+// the removed values or nil if sd is empty. The values are returned in
+// the order PopBack would remove them, i.e. the last value of sd comes first.
+func (sd *SyncDeque[T]) PopBackMany(max int) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopBackMany(max)
+}
+
+// PopBackManyWithBuffer is similar to PopBackMany except that it uses
+// buf to store the removed values as long as it has enough space.
+func (sd *SyncDeque[T]) PopBackManyWithBuffer(max int, buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.PopBackManyWithBuffer(max, buf)
+}
+
+// DequeueManyFromBack is similar to PopBackMany except that the removed
+// values keep their original order, i.e. the last value of sd comes last.
+func (sd *SyncDeque[T]) DequeueManyFromBack(max int) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DequeueManyFromBack(max)
+}
+
+// DequeueManyFromBackWithBuffer is similar to DequeueManyFromBack except that
+// it uses buf to store the removed values as long as it has enough space.
+func (sd *SyncDeque[T]) DequeueManyFromBackWithBuffer(max int, buf []T) []T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DequeueManyFromBackWithBuffer(max, buf)
+}
+
+// DiscardFront removes at most n values from the front of sd and returns
+// the number of the removed values.
+func (sd *SyncDeque[T]) DiscardFront(n int) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DiscardFront(n)
+}
+
+// DiscardBack removes at most n values from the back of sd and returns
+// the number of the removed values.
+func (sd *SyncDeque[T]) DiscardBack(n int) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.DiscardBack(n)
+}
+
+// Rotate moves n values from the front of sd to the back if n > 0, or -n values
+// from the back of sd to the front if n < 0.
+func (sd *SyncDeque[T]) Rotate(n int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Rotate(n)
+}
+
+// Back returns the last value of sd if any. The return value ok
+// indicates whether it succeeded.
+func (sd *SyncDeque[T]) Back() (_ T, ok bool) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Back()
+}
+
+// Front returns the first value of sd if any. The return value ok
+// indicates whether it succeeded.
+func (sd *SyncDeque[T]) Front() (_ T, ok bool) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Front()
+}
+
+// IsEmpty returns whether sd is empty.
+func (sd *SyncDeque[T]) IsEmpty() bool {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.IsEmpty()
+}
+
+// Len returns the number of values in sd.
+func (sd *SyncDeque[T]) Len() int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Len()
+}
+
+// MaxLen returns the max length of sd, or 0 if sd has no limit.
+func (sd *SyncDeque[T]) MaxLen() int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.MaxLen()
+}
+
+// Bytes returns the total size of all the values in sd. See Deque.Bytes for details.
+func (sd *SyncDeque[T]) Bytes() int64 {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Bytes()
+}
+
+// Enqueue is an alias of PushBack.
+func (sd *SyncDeque[T]) Enqueue(v T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Enqueue(v)
+}
+
+// TryDequeue is an alias of TryPopFront.
+func (sd *SyncDeque[T]) TryDequeue() (_ T, ok bool) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TryDequeue()
+}
+
+// Dequeue is an alias of PopFront.
+func (sd *SyncDeque[T]) Dequeue() T {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.Dequeue()
+}
+
+// Dump returns all the values in sd.
+func (sd *SyncDeque[T]) Dump() []T {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Dump()
+}
+
+// Range iterates all the values in sd. Do NOT call the methods of sd in f.
+func (sd *SyncDeque[T]) Range(f func(i int, v T) bool) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	sd.dq.Range(f)
+}
+
+// Peek returns the value at idx. It panics if idx is out of range.
+func (sd *SyncDeque[T]) Peek(idx int) T {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.Peek(idx)
+}
+
+// Replace replaces the value at idx with v. It panics if idx is out of range.
+func (sd *SyncDeque[T]) Replace(idx int, v T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Replace(idx, v)
+}
+
+// Swap exchanges the two values at idx1 and idx2. It panics if idx1 or idx2 is out of range.
+func (sd *SyncDeque[T]) Swap(idx1, idx2 int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Swap(idx1, idx2)
+}
+
+// Insert inserts a new value v before the value at idx. See Deque.Insert for details.
+func (sd *SyncDeque[T]) Insert(idx int, v T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Insert(idx, v)
+}
+
+// InsertSlice inserts all the values in vs before the value at idx, keeping their
+// order. See Deque.InsertSlice for details.
+func (sd *SyncDeque[T]) InsertSlice(idx int, vs []T) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.InsertSlice(idx, vs)
+}
+
+// Remove removes the value at idx. It panics if idx is out of range.
+func (sd *SyncDeque[T]) Remove(idx int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Remove(idx)
+}
+
+// RemoveRange removes the values in [from, to). It panics if from or to is out of range,
+// or if from > to.
+func (sd *SyncDeque[T]) RemoveRange(from, to int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.RemoveRange(from, to)
+}
+
+// RemoveFunc removes all the values satisfying pred from sd and returns the number
+// of the removed values.
+func (sd *SyncDeque[T]) RemoveFunc(pred func(T) bool) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.RemoveFunc(pred)
+}
+
+// Retain removes all the values not satisfying pred from sd and returns the number
+// of the removed values.
+func (sd *SyncDeque[T]) Retain(pred func(T) bool) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.Retain(pred)
+}
+
+// Clear removes all the values from sd.
+func (sd *SyncDeque[T]) Clear() {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Clear()
+}
+
+// AppendDeque moves all the values of src to the back of sd, keeping their order.
+// src must not be used by other goroutines during the call. See Deque.AppendDeque
+// for details.
+func (sd *SyncDeque[T]) AppendDeque(src *Deque[T]) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.AppendDeque(src)
+}
+
+// PrependDeque moves all the values of src to the front of sd, keeping their order.
+// src must not be used by other goroutines during the call. See Deque.PrependDeque
+// for details.
+func (sd *SyncDeque[T]) PrependDeque(src *Deque[T]) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.PrependDeque(src)
+}
+
+// TransferFrontTo moves at most n values from the front of sd to the back of dst,
+// keeping their order, and returns the number of the moved values. dst must not
+// be used by other goroutines during the call. See Deque.TransferFrontTo for details.
+func (sd *SyncDeque[T]) TransferFrontTo(dst *Deque[T], n int) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.TransferFrontTo(dst, n)
+}
+
+// SplitAt splits sd into two at idx. sd keeps the values in [0, idx), and the
+// values in [idx, sd.Len()) are moved to a new Deque, which is returned. It
+// panics if idx is out of range.
+func (sd *SyncDeque[T]) SplitAt(idx int) *Deque[T] {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.callbacks.unwrap(sd.dq.SplitAt(idx))
+}
+
+// Clone returns a copy of sd as a Deque.
+func (sd *SyncDeque[T]) Clone() *Deque[T] {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.callbacks.unwrap(sd.dq.Clone())
+}
+
+// CloneFunc is similar to Clone except that every value is copied by calling
+// copyElem. See Deque.CloneFunc for details.
+func (sd *SyncDeque[T]) CloneFunc(copyElem func(T) T) *Deque[T] {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.callbacks.unwrap(sd.dq.CloneFunc(copyElem))
+}
+
+// Reverse reverses the order of the values in sd in place.
+func (sd *SyncDeque[T]) Reverse() {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.Reverse()
+}
+
+// SortFunc sorts the values in sd in ascending order as determined by cmp.
+// See Deque.SortFunc for details.
+func (sd *SyncDeque[T]) SortFunc(cmp func(a, b T) int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.SortFunc(cmp)
+}
+
+// SortStableFunc is similar to SortFunc except that it keeps the original
+// order of equal values.
+func (sd *SyncDeque[T]) SortStableFunc(cmp func(a, b T) int) {
+	sd.mu.Lock()
+	defer sd.unlock()
+	sd.dq.SortStableFunc(cmp)
+}
+
+// Partition moves the values for which pred returns true to the front of sd,
+// and the others to the back. See Deque.Partition for details.
+func (sd *SyncDeque[T]) Partition(pred func(T) bool) int {
+	sd.mu.Lock()
+	defer sd.unlock()
+	return sd.dq.Partition(pred)
+}
+
+// BinarySearchFunc searches for target in sd, which must be sorted by cmp.
+// See Deque.BinarySearchFunc for details.
+func (sd *SyncDeque[T]) BinarySearchFunc(target T, cmp func(a, b T) int) (int, bool) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.BinarySearchFunc(target, cmp)
+}
+
+// IndexFunc returns the index of the first value satisfying f, or -1 if none do.
+func (sd *SyncDeque[T]) IndexFunc(f func(T) bool) int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.IndexFunc(f)
+}
+
+// LastIndexFunc returns the index of the last value satisfying f, or -1 if none do.
+func (sd *SyncDeque[T]) LastIndexFunc(f func(T) bool) int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.LastIndexFunc(f)
+}
+
+// ContainsFunc returns whether at least one value in sd satisfies f.
+func (sd *SyncDeque[T]) ContainsFunc(f func(T) bool) bool {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.ContainsFunc(f)
+}
+
+// CountFunc returns the number of the values in sd satisfying f.
+func (sd *SyncDeque[T]) CountFunc(f func(T) bool) int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.CountFunc(f)
+}
+
+// EqualFunc returns whether sd and other have the same length and eq returns
+// true for each pair of values at the same index. other must not be modified
+// by other goroutines during the call.
+func (sd *SyncDeque[T]) EqualFunc(other *Deque[T], eq func(a, b T) bool) bool {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dq.EqualFunc(other, eq)
+}
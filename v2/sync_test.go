@@ -0,0 +1,238 @@
+package deque
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// The tests of SyncDeque are meant to be run with -race, e.g. by coverage.sh.
+
+//gocyclo:ignore
+func TestSyncDeque_Concurrent(t *testing.T) {
+	const producers, consumers, total = 4, 4, 10000
+	sd := NewSyncDeque[int](WithChunkSize(8))
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < total; i++ {
+				v := p*total + i
+				switch i % 4 {
+				case 0:
+					sd.PushFront(v)
+				case 1:
+					sd.PushBackSlice([]int{v})
+				case 2:
+					sd.Insert(sd.Len()/2, v)
+				default:
+					sd.PushBack(v)
+				}
+			}
+		}(p)
+	}
+
+	results := make([][]int, consumers)
+	var finished int32
+	go func() {
+		wg.Wait()
+		atomic.StoreInt32(&finished, 1)
+	}()
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			var buf []int
+			for {
+				switch c % 3 {
+				case 0:
+					if v, ok := sd.TryPopFront(); ok {
+						results[c] = append(results[c], v)
+						continue
+					}
+				case 1:
+					if v, ok := sd.TryPopBack(); ok {
+						results[c] = append(results[c], v)
+						continue
+					}
+				default:
+					buf = sd.DequeueManyWithBuffer(16, buf)
+					if len(buf) > 0 {
+						results[c] = append(results[c], buf...)
+						continue
+					}
+				}
+				_ = sd.Snapshot()
+				_ = sd.Len()
+				if atomic.LoadInt32(&finished) == 1 && sd.IsEmpty() {
+					return
+				}
+			}
+		}(c)
+	}
+	cwg.Wait()
+
+	var all []int
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	all = sd.DrainTo(all)
+	if len(all) != producers*total {
+		t.Fatalf("unexpected number of values: %d", len(all))
+	}
+	sort.Ints(all)
+	for i, v := range all {
+		if v != i {
+			t.Fatalf("unexpected value: %d", v)
+		}
+	}
+}
+
+func TestSyncDeque_PushBackIfLen(t *testing.T) {
+	sd := NewSyncDeque[int]()
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var added int
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if sd.PushBackIfLen(g*1000+i, 100) {
+					mu.Lock()
+					added++
+					mu.Unlock()
+				}
+				if i%3 == 0 {
+					sd.PopFrontIf(func(v int) bool {
+						return v%2 == 0
+					})
+				}
+				if sd.Len() > 100 {
+					t.Error(`sd.Len() > 100`)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	var removed int
+	for {
+		if _, ok := sd.PopFrontIf(func(int) bool { return true }); !ok {
+			break
+		}
+		removed++
+	}
+	if added < 100 || removed > 100 {
+		t.Fatal(`added < 100 || removed > 100`)
+	}
+	if sd.PushBackIfLen(1, 0) {
+		t.Fatal(`sd.PushBackIfLen(1, 0) should return false`)
+	}
+}
+
+func TestSyncDeque_Do(t *testing.T) {
+	sd := NewSyncDeque[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				sd.Do(func(dq *Deque[int]) {
+					n := 0
+					if v, ok := dq.TryPopBack(); ok {
+						n = v
+					}
+					dq.PushBack(n + 1)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	checkBufs(nil, sd.Snapshot(), []int{8000}, "", t)
+}
+
+func TestSyncDeque_Callbacks(t *testing.T) {
+	var sd *SyncDeque[int]
+	var evicted, lens []int
+	sd = NewSyncDeque[int](WithMaxLen(3), WithOnEvict(func(v int) {
+		// The callbacks can call back into sd without a deadlock.
+		evicted = append(evicted, v)
+		lens = append(lens, sd.Len())
+	}), WithWatermarks(3, 1, func() {
+		lens = append(lens, -sd.Len())
+	}, func() {
+		sd.PushBack(100)
+	}))
+
+	sd.PushBackSlice([]int{1, 2, 3, 4})
+	checkBufs(nil, evicted, []int{1}, "", t)
+	checkBufs(nil, lens, []int{3, -3}, "", t)
+	sd.Do(func(dq *Deque[int]) {
+		dq.PushBack(5)
+		if len(evicted) != 1 {
+			t.Fatal(`the callbacks should be called after Do returns`)
+		}
+	})
+	checkBufs(nil, evicted, []int{1, 2}, "", t)
+
+	sd.DiscardFront(2)
+	checkBufs(nil, sd.Dump(), []int{5, 100}, "", t)
+	nd := sd.Clone()
+	nd.PushBackSlice([]int{6, 7})
+	checkBufs(nil, evicted, []int{1, 2, 5}, "", t)
+	if len(sd.callbacks.pending) != 0 || len(nd.Dump()) != 3 {
+		t.Fatal(`len(sd.callbacks.pending) != 0 || len(nd.Dump()) != 3`)
+	}
+}
+
+func TestSyncDeque_Methods(t *testing.T) {
+	sd := NewSyncDequeFromSlice([]int{3, 1, 2}, WithMaxLen(10))
+	sd.SortFunc(func(a, b int) int { return a - b })
+	sd.PushFront(0)
+	sd.Enqueue(4)
+	sd.InsertSlice(5, []int{5, 6})
+	sd.Rotate(1)
+	sd.Rotate(-1)
+	checkBufs(nil, sd.Dump(), []int{0, 1, 2, 3, 4, 5, 6}, "", t)
+	if sd.MaxLen() != 10 || sd.Bytes() != 0 {
+		t.Fatal(`sd.MaxLen() != 10 || sd.Bytes() != 0`)
+	}
+	if i, ok := sd.BinarySearchFunc(4, func(a, b int) int { return a - b }); i != 4 || !ok {
+		t.Fatal(`i != 4 || !ok`)
+	}
+	if sd.IndexFunc(func(v int) bool { return v > 2 }) != 3 {
+		t.Fatal(`sd.IndexFunc(func(v int) bool { return v > 2 }) != 3`)
+	}
+	if sd.Peek(2) != 2 || sd.CountFunc(func(v int) bool { return v%2 == 0 }) != 4 {
+		t.Fatal(`sd.Peek(2) != 2 || sd.CountFunc(func(v int) bool { return v%2 == 0 }) != 4`)
+	}
+	if !sd.EqualFunc(sd.Clone(), func(a, b int) bool { return a == b }) {
+		t.Fatal(`sd.EqualFunc should return true`)
+	}
+
+	dst := NewDeque[int]()
+	if sd.TransferFrontTo(dst, 2) != 2 {
+		t.Fatal(`sd.TransferFrontTo(dst, 2) != 2`)
+	}
+	sd.PrependDeque(dst)
+	nd := sd.SplitAt(5)
+	checkBufs(nil, nd.Dump(), []int{5, 6}, "", t)
+	sd.Reverse()
+	if v := sd.PopFront(); v != 4 {
+		t.Fatal(`v != 4`)
+	}
+	if n := sd.RemoveFunc(func(v int) bool { return v == 0 }); n != 1 {
+		t.Fatal(`n != 1`)
+	}
+	checkBufs(nil, sd.DrainTo([]int{9}), []int{9, 3, 2, 1}, "", t)
+	if sd.DrainTo(nil) != nil || !sd.IsEmpty() {
+		t.Fatal(`sd.DrainTo(nil) != nil || !sd.IsEmpty()`)
+	}
+}
@@ -0,0 +1,208 @@
+package deque
+
+import (
+	"fmt"
+)
+
+// AppendDeque moves all the values of src to the back of dq, keeping their order.
+// src becomes empty after the call. When both deques share the same chunk size,
+// the chunks of src are moved to dq as a whole instead of being copied.
+//
+// If dq has a max length or a byte limit, AppendDeque follows the overflow policy
+// of dq. With Reject, the values that do not fit stay in src.
+func (dq *Deque[T]) AppendDeque(src *Deque[T]) {
+	if src == dq {
+		return
+	}
+	src.TransferFrontTo(dq, src.count)
+}
+
+// PrependDeque moves all the values of src to the front of dq, keeping their order.
+// src becomes empty after the call. When both deques share the same chunk size,
+// the chunks of src are moved to dq as a whole instead of being copied.
+//
+// If dq has a max length, PrependDeque follows the overflow policy of dq like
+// PushFrontSlice does. With Reject, the values that do not fit stay in src. If dq
+// has a byte limit, the values are moved one by one as if by PushFront, and with
+// Reject, the move stops at the first value that does not fit.
+func (dq *Deque[T]) PrependDeque(src *Deque[T]) {
+	if src == dq || src.count == 0 {
+		return
+	}
+	if dq.sizeOf != nil {
+		dq.prependEach(src)
+		return
+	}
+
+	n, drop := src.count, 0
+	if room := dq.maxLen - dq.count; n > room {
+		switch dq.policy {
+		case DropNewest:
+			n, drop = room, n-room
+		case Reject:
+			n = room
+		case Panic:
+			panic(errFull)
+		}
+	}
+
+	src.cancelReservation()
+	dq.cancelReservation()
+	if dq.count == 0 {
+		dq.Clear()
+	}
+
+	remaining := n
+	if src.chunkSize == dq.chunkSize {
+		moved := 0
+		for remaining > 0 {
+			c := src.chunks[len(src.chunks)-1]
+			num := c.e - c.s
+			if num > remaining {
+				break
+			}
+			src.detachEnd()
+			src.count -= num
+			src.bytes -= src.sizeOfSlice(c.data[c.s:c.e])
+			dq.prependChunk(c)
+			dq.count += num
+			remaining -= num
+			moved++
+		}
+		dq.mergeChunks(moved - 1)
+	}
+
+	for remaining > 0 {
+		c := src.chunks[len(src.chunks)-1]
+		num := minInt(remaining, c.e-c.s)
+		dq.pushFrontSlice(c.data[c.e-num : c.e])
+		src.DiscardBack(num)
+		remaining -= num
+	}
+
+	dq.dropBack(src, drop)
+	if excess := dq.count - dq.maxLen; excess > 0 {
+		dq.evictBack(excess)
+	}
+	src.watch()
+	dq.watch()
+}
+
+// TransferFrontTo moves at most n values from the front of dq to the back of dst,
+// keeping their order, and returns the number of the moved values. When both
+// deques share the same chunk size, the chunks fully covered by the transfer are
+// moved as a whole and only the values of the boundary chunk are copied.
+//
+// If dst has a max length, TransferFrontTo follows the overflow policy of dst.
+// With DropNewest, the values that do not fit are removed from dq but are not
+// counted as moved. With Reject, they stay in dq. If dst has a byte limit, the
+// values are moved one by one as if by PushBack, and with Reject, the move stops
+// at the first value that does not fit.
+func (dq *Deque[T]) TransferFrontTo(dst *Deque[T], n int) int {
+	n = minInt(n, dq.count)
+	if n <= 0 {
+		return 0
+	}
+	if dst == dq {
+		dq.Rotate(n)
+		return n
+	}
+	if dst.sizeOf != nil {
+		return dq.transferEach(dst, n)
+	}
+
+	drop := 0
+	if room := dst.maxLen - dst.count; n > room {
+		switch dst.policy {
+		case DropNewest:
+			n, drop = room, n-room
+		case Reject:
+			n = room
+		case Panic:
+			panic(errFull)
+		}
+	}
+
+	dq.cancelReservation()
+	dst.cancelReservation()
+	if dst.count == 0 {
+		dst.Clear()
+	}
+
+	remaining := n
+	if dst.chunkSize == dq.chunkSize {
+		junction := len(dst.chunks) - 1
+		for remaining > 0 {
+			c := dq.chunks[0]
+			num := c.e - c.s
+			if num > remaining {
+				break
+			}
+			dq.detachStart()
+			dq.count -= num
+			dq.bytes -= dq.sizeOfSlice(c.data[c.s:c.e])
+			dst.appendChunk(c)
+			dst.count += num
+			remaining -= num
+		}
+		dst.mergeChunks(junction)
+	}
+
+	for remaining > 0 {
+		c := dq.chunks[0]
+		num := minInt(remaining, c.e-c.s)
+		dst.pushBackSlice(c.data[c.s : c.s+num])
+		dq.DiscardFront(num)
+		remaining -= num
+	}
+
+	dst.dropFront(dq, drop)
+	if excess := dst.count - dst.maxLen; excess > 0 {
+		dst.evictFront(excess)
+	}
+	dq.watch()
+	dst.watch()
+	return n
+}
+
+// SplitAt splits dq into two at idx. dq keeps the values in [0, idx), and the
+// values in [idx, dq.Len()) are moved to a new Deque, which is returned. The
+// chunks after idx are moved to the new Deque as a whole, and only the values
+// of the chunk holding idx are copied. It panics if idx is out of range.
+func (dq *Deque[T]) SplitAt(idx int) *Deque[T] {
+	if idx < 0 || idx > dq.count {
+		panic(fmt.Errorf("out of range: %d", idx))
+	}
+
+	dq.cancelReservation()
+	nd := dq.newSibling()
+	if idx == dq.count {
+		return nd
+	}
+	if dq.sizeOf != nil {
+		nd.bytes = dq.sizeOfRange(idx, dq.count)
+		dq.bytes -= nd.bytes
+	}
+
+	j, k := dq.locate(idx)
+	for i := len(dq.chunks) - 1; i > j; i-- {
+		nd.prependChunk(dq.detachEnd())
+	}
+	if c := dq.chunks[j]; k == c.s {
+		nd.prependChunk(dq.detachEnd())
+	} else {
+		nd.pushFrontSlice(c.data[k:c.e])
+		var defVal T
+		for i := k; i < c.e; i++ {
+			c.data[i] = defVal
+		}
+		c.e = k
+		dq.mergeChunks(j - 1)
+		nd.mergeChunks(0)
+	}
+
+	nd.count = dq.count - idx
+	dq.count = idx
+	dq.watch()
+	return nd
+}
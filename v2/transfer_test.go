@@ -0,0 +1,146 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func newTransferDeques(r *rand.Rand, total1, total2, chunkSize int) (*Deque[int], []int, *Deque[int], []int) {
+	dq1, a1 := newRandomDeque(r, total1)
+	dq2 := NewDeque[int](WithChunkSize(chunkSize))
+	var a2 []int
+	for i := 0; i < total2; i++ {
+		v := 10000 + i
+		if i%3 == 0 {
+			dq2.PushFront(v)
+			a2 = append([]int{v}, a2...)
+		} else {
+			dq2.PushBack(v)
+			a2 = append(a2, v)
+		}
+	}
+	return dq1, a1, dq2, a2
+}
+
+func checkTransfer(t *testing.T, dq *Deque[int], expected []int, str string) {
+	t.Helper()
+	invariant(t, dq)
+	if dq.Len() != len(expected) {
+		t.Fatalf("dq.Len() != len(expected). %s", str)
+	}
+	checkValues(t, dq, expected...)
+}
+
+func TestDeque_AppendDeque(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, chunkSize := range []int{8, 16} {
+		for _, total1 := range []int{0, 1, 7, 8, 9, 100} {
+			for _, total2 := range []int{0, 1, 7, 8, 9, 100} {
+				dst, a1, src, a2 := newTransferDeques(r, total1, total2, chunkSize)
+				str := fmt.Sprintf("chunkSize: %d, total1: %d, total2: %d", chunkSize, total1, total2)
+				var first *chunk[int]
+				if len(src.chunks) > 0 {
+					first = src.chunks[0]
+				}
+				dst.AppendDeque(src)
+				checkTransfer(t, dst, append(append([]int(nil), a1...), a2...), str)
+				checkTransfer(t, src, nil, str)
+				if chunkSize == 8 && total2 > 1 {
+					found := false
+					for _, c := range dst.chunks {
+						found = found || c == first
+					}
+					if !found {
+						t.Fatalf("the chunks of src should be moved to dst. %s", str)
+					}
+				}
+			}
+		}
+	}
+
+	dq := NewDequeFromSlice([]int{1, 2, 3})
+	dq.AppendDeque(dq)
+	checkTransfer(t, dq, []int{1, 2, 3}, "self")
+}
+
+func TestDeque_PrependDeque(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, chunkSize := range []int{8, 16} {
+		for _, total1 := range []int{0, 1, 7, 8, 9, 100} {
+			for _, total2 := range []int{0, 1, 7, 8, 9, 100} {
+				dst, a1, src, a2 := newTransferDeques(r, total1, total2, chunkSize)
+				str := fmt.Sprintf("chunkSize: %d, total1: %d, total2: %d", chunkSize, total1, total2)
+				dst.PrependDeque(src)
+				checkTransfer(t, dst, append(append([]int(nil), a2...), a1...), str)
+				checkTransfer(t, src, nil, str)
+				dst.PushFront(-1)
+				dst.PushBack(-2)
+				invariant(t, dst)
+			}
+		}
+	}
+
+	dq := NewDequeFromSlice([]int{1, 2, 3})
+	dq.PrependDeque(dq)
+	checkTransfer(t, dq, []int{1, 2, 3}, "self")
+}
+
+func TestDeque_TransferFrontTo(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, chunkSize := range []int{8, 16} {
+		for _, total1 := range []int{0, 1, 9, 100} {
+			for _, total2 := range []int{0, 1, 7, 8, 9, 100} {
+				for _, n := range []int{-1, 0, 1, 5, 8, 9, 17, 50, 200} {
+					dst, a1, src, a2 := newTransferDeques(r, total1, total2, chunkSize)
+					str := fmt.Sprintf("chunkSize: %d, total1: %d, total2: %d, n: %d", chunkSize, total1, total2, n)
+					expected := minInt(maxInt(n, 0), total2)
+					if m := src.TransferFrontTo(dst, n); m != expected {
+						t.Fatalf("unexpected result of TransferFrontTo: %d. %s", m, str)
+					}
+					checkTransfer(t, dst, append(append([]int(nil), a1...), a2[:expected]...), str)
+					checkTransfer(t, src, a2[expected:], str)
+					src.PushFront(-1)
+					dst.PushBack(-2)
+					invariant(t, src)
+					invariant(t, dst)
+				}
+			}
+		}
+	}
+
+	dq := NewDequeFromSlice([]int{1, 2, 3, 4, 5})
+	if n := dq.TransferFrontTo(dq, 2); n != 2 {
+		t.Fatal(`n != 2`)
+	}
+	checkTransfer(t, dq, []int{3, 4, 5, 1, 2}, "self")
+}
+
+func TestDeque_SplitAt(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, total := range []int{0, 1, 7, 8, 9, 100, 1000} {
+		for idx := 0; idx <= total-total/10; idx += 1 + idx/8 {
+			dq, a := newRandomDeque(r, total)
+			str := fmt.Sprintf("total: %d, idx: %d", total, idx)
+			nd := dq.SplitAt(idx)
+			if nd.chunkSize != dq.chunkSize {
+				t.Fatalf("nd.chunkSize != dq.chunkSize. %s", str)
+			}
+			checkTransfer(t, dq, a[:idx], str)
+			checkTransfer(t, nd, a[idx:], str)
+			dq.PushBack(-1)
+			nd.PushFront(-2)
+			invariant(t, dq)
+			invariant(t, nd)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal(`SplitAt should panic`)
+			}
+		}()
+		NewDequeFromSlice([]int{1, 2, 3}).SplitAt(4)
+	}()
+}
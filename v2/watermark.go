@@ -0,0 +1,63 @@
+package deque
+
+// WithWatermarks sets the high and low watermarks of a Deque, which is useful for
+// backpressure. onHigh is called when the length of the Deque reaches high, and
+// onLow is called when the length of the Deque drops to low after onHigh has been
+// called. The two callbacks are called alternately, so a Deque whose length stays
+// around one of the watermarks does not keep calling them. Either callback can be
+// nil. high <= 0 means no watermarks, and NewDeque panics unless 0 <= low < high.
+//
+// The callbacks are called after the Deque is modified. Do NOT modify the Deque
+// in them. A SyncDeque or a BlockingDeque calls them after releasing its lock, so
+// they may call its methods. The watermarks are not copied to the Deques created
+// by Clone, CloneFunc and SplitAt.
+func WithWatermarks(high, low int, onHigh, onLow func()) Option {
+	return func(holder *optionHolder) {
+		holder.high = high
+		holder.low = low
+		holder.onHigh = onHigh
+		holder.onLow = onLow
+	}
+}
+
+// updateLimits updates dq.limit and dq.floor, so that PushBack, PushFront and the
+// pop methods only take the slow path when they have to deal with the max length,
+// the byte limit or the watermarks of dq.
+func (dq *Deque[T]) updateLimits() {
+	dq.limit, dq.floor = dq.maxLen, -1
+	if dq.sizeOf != nil {
+		dq.limit, dq.floor = 0, maxLenUnlimited
+	}
+	if dq.above {
+		dq.floor = maxInt(dq.floor, dq.low)
+	} else {
+		dq.limit = minInt(dq.limit, dq.high-1)
+	}
+}
+
+// popped is called when r has been removed by a pop method and dq.count <= dq.floor.
+func (dq *Deque[T]) popped(r T) {
+	if dq.sizeOf != nil {
+		dq.bytes -= dq.sizeOf(r)
+	}
+	dq.watch()
+}
+
+// watch calls the watermark callbacks of dq if its length has crossed one of
+// its watermarks.
+func (dq *Deque[T]) watch() {
+	switch {
+	case !dq.above && dq.count >= dq.high:
+		dq.above = true
+		dq.updateLimits()
+		if dq.onHigh != nil {
+			dq.onHigh()
+		}
+	case dq.above && dq.count <= dq.low:
+		dq.above = false
+		dq.updateLimits()
+		if dq.onLow != nil {
+			dq.onLow()
+		}
+	}
+}
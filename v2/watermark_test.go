@@ -0,0 +1,197 @@
+package deque
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+//gocyclo:ignore
+func TestDeque_Watermarks(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, marks := range [][2]int{{1, 0}, {2, 1}, {10, 5}, {20, 0}, {50, 49}} {
+		high, low := marks[0], marks[1]
+		var events, expected []string
+		dq := NewDeque[int](WithChunkSize(8), WithWatermarks(high, low, func() {
+			events = append(events, "high")
+		}, func() {
+			events = append(events, "low")
+		}))
+
+		var a []int
+		var above bool
+		var next int
+		for i := 0; i < 5000; i++ {
+			op := r.Intn(16)
+			str := fmt.Sprintf("high: %d, low: %d, i: %d, op: %d", high, low, i, op)
+			switch op {
+			case 0, 1:
+				next++
+				dq.PushBack(next)
+				a = append(a, next)
+			case 2, 3:
+				next++
+				dq.PushFront(next)
+				a = append([]int{next}, a...)
+			case 4, 5:
+				if len(a) > 0 {
+					dq.PopFront()
+					a = a[1:]
+				}
+			case 6:
+				if _, ok := dq.TryPopBack(); ok {
+					a = a[:len(a)-1]
+				}
+			case 7:
+				vs := newValues(r, &next, 10)
+				dq.PushBackSlice(vs)
+				a = append(a, vs...)
+			case 8:
+				vs := newValues(r, &next, 10)
+				idx := r.Intn(len(a) + 1)
+				dq.InsertSlice(idx, vs)
+				a = append(append(append([]int(nil), a[:idx]...), vs...), a[idx:]...)
+			case 9:
+				n := 1 + r.Intn(10)
+				dq.DequeueMany(n)
+				a = a[minInt(n, len(a)):]
+			case 10:
+				n := r.Intn(10)
+				dq.DiscardBack(n)
+				a = a[:len(a)-minInt(n, len(a))]
+			case 11:
+				from := r.Intn(len(a) + 1)
+				to := from + r.Intn(len(a)-from+1)
+				dq.RemoveRange(from, to)
+				a = append(a[:from:from], a[to:]...)
+			case 12:
+				dq.RemoveFunc(func(v int) bool { return v%3 == 0 })
+				var b []int
+				for _, v := range a {
+					if v%3 != 0 {
+						b = append(b, v)
+					}
+				}
+				a = b
+			case 13:
+				vs := newValues(r, &next, 10)
+				src := NewDequeFromSlice(vs)
+				k := r.Intn(len(vs) + 1)
+				src.TransferFrontTo(dq, k)
+				a = append(a, vs[:k]...)
+			case 14:
+				k := r.Intn(len(a) + 1)
+				dst := NewDeque[int]()
+				dq.TransferFrontTo(dst, k)
+				a = a[k:]
+			case 15:
+				if r.Intn(10) == 0 {
+					dq.Clear()
+					a = nil
+				}
+			}
+
+			switch {
+			case !above && len(a) >= high:
+				above = true
+				expected = append(expected, "high")
+			case above && len(a) <= low:
+				above = false
+				expected = append(expected, "low")
+			}
+			if dq.Len() != len(a) {
+				t.Fatalf("dq.Len() != len(a). %s", str)
+			}
+			if fmt.Sprint(events) != fmt.Sprint(expected) {
+				t.Fatalf("unexpected events: %v. %s", events, str)
+			}
+		}
+		invariant(t, dq, skipChunkMerge())
+		checkValues(t, dq, a...)
+	}
+}
+
+func TestDeque_WatermarksBounded(t *testing.T) {
+	var events []string
+	onHigh := func() {
+		events = append(events, "high")
+	}
+	onLow := func() {
+		events = append(events, "low")
+	}
+
+	dq := NewDeque[int](WithMaxLen(3), WithWatermarks(3, 1, onHigh, onLow))
+	for i := 0; i < 10; i++ {
+		dq.PushBack(i)
+	}
+	checkValues(t, dq, 7, 8, 9)
+	dq.PopFront()
+	dq.PopFront()
+	dq.PushFront(1)
+	dq.PushFront(2)
+	if fmt.Sprint(events) != "[high low high]" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+
+	events = nil
+	dq = NewDeque[int](WithMaxBytes(12, sizeOfInt), WithWatermarks(2, 0, onHigh, onLow))
+	dq.PushBack(5)
+	dq.PushBack(5)
+	dq.PushBack(5)
+	dq.Replace(0, 3)
+	dq.PopBack()
+	dq.PopBack()
+	if fmt.Sprint(events) != "[high low]" {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if dq.Bytes() != 0 {
+		t.Fatal(`dq.Bytes() != 0`)
+	}
+	if dq.Clone().high != maxLenUnlimited {
+		t.Fatal(`dq.Clone().high != maxLenUnlimited`)
+	}
+}
+
+func TestDeque_WatermarksPartition(t *testing.T) {
+	var events []string
+	dq := NewDeque[int](WithChunkSize(8), WithWatermarks(10, 2, func() {
+		events = append(events, "high")
+	}, func() {
+		events = append(events, "low")
+	}))
+	for i := 0; i < 10; i++ {
+		dq.PushBack(i)
+	}
+	if n := dq.Partition(func(v int) bool { return v == 0 }); n != 1 {
+		t.Fatal(`n != 1`)
+	}
+	dq.PushBack(10)
+	if fmt.Sprint(events) != "[high]" || !dq.above {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	checkValues(t, dq, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+
+	Compact(dq)
+	dq.Retain(func(v int) bool { return v < 5 })
+	dq.RemoveFunc(func(v int) bool { return v > 1 })
+	if fmt.Sprint(events) != "[high low]" || dq.above {
+		t.Fatalf("unexpected events: %v", events)
+	}
+}
+
+func TestWithWatermarks(t *testing.T) {
+	dq := NewDeque[int](WithWatermarks(0, 5, nil, nil))
+	dq.PushBackSlice(make([]int, 100))
+	dq.Clear()
+
+	for _, marks := range [][2]int{{5, 5}, {5, -1}, {5, 6}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal(`NewDeque should panic`)
+				}
+			}()
+			NewDeque[int](WithWatermarks(marks[0], marks[1], nil, nil))
+		}()
+	}
+}